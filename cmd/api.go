@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"tempest-cli/tempest"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	apiRetries   int
+	apiRateLimit int
+	apiURL       string
+	wsURL        string
+	verbose      bool
+	noCache      bool
+	cacheMaxAge  time.Duration
+
+	limiterOnce   sync.Once
+	sharedLimiter *tempest.RateLimiter
+)
+
+// newAPIClient returns a REST client authenticated with the configured token.
+func newAPIClient() (*tempest.Client, error) {
+	token, err := getAPIToken()
+	if err != nil {
+		return nil, err
+	}
+	return newAPIClientWithToken(token), nil
+}
+
+// newAPIClientWithToken returns a REST client honoring the retry, rate limit
+// and verbosity flags. Every client shares one rate limiter, kept in a file
+// so that commands running at the same time share it too.
+func newAPIClientWithToken(token string) *tempest.Client {
+	limiterOnce.Do(func() {
+		path, err := tempest.DefaultRateLimiterPath()
+		if err != nil {
+			verboseLogf("rate limit not shared with other processes: %v", err)
+			sharedLimiter = tempest.NewRateLimiter(apiRateLimit, 1)
+			return
+		}
+		sharedLimiter = tempest.NewSharedRateLimiter(path, apiRateLimit, 1)
+	})
+
+	retry := tempest.DefaultRetryPolicy
+	retry.MaxRetries = apiRetries
+
+	opts := []tempest.Option{
+		tempest.WithRetry(retry),
+		tempest.WithRateLimiter(sharedLimiter),
+	}
+	if apiURL != "" {
+		opts = append(opts, tempest.WithBaseURL(apiURL))
+	}
+	if verbose {
+		opts = append(opts, tempest.WithLogger(verboseLogf))
+	}
+	if !noCache {
+		if dir, err := tempest.DefaultCacheDir(); err == nil {
+			policy := tempest.DefaultCachePolicy
+			policy.MaxAge = cacheMaxAge
+			opts = append(opts, tempest.WithCache(tempest.NewFileCache(dir), policy))
+		} else {
+			verboseLogf("response cache disabled: %v", err)
+		}
+	}
+	return tempest.NewClient(token, opts...)
+}
+
+// liveWSURL returns the WebSocket endpoint to connect to: --ws-url, or the
+// one matching --api-url when only that was changed, or the real one.
+func liveWSURL() string {
+	if wsURL != "" || apiURL == "" {
+		return cmp.Or(wsURL, tempest.DefaultWSURL)
+	}
+	u, err := url.Parse(apiURL)
+	if err != nil {
+		return tempest.DefaultWSURL
+	}
+	u.Scheme = strings.Replace(u.Scheme, "http", "ws", 1)
+	u.Path = strings.TrimSuffix(strings.TrimSuffix(u.Path, "/"), "/rest") + "/data"
+	return u.String()
+}
+
+// withResponseInfo returns a context that records where the response of the
+// next API call came from.
+func withResponseInfo(ctx context.Context) (context.Context, *tempest.ResponseInfo) {
+	info := &tempest.ResponseInfo{}
+	return tempest.WithResponseInfo(ctx, info), info
+}
+
+// reportStale prints a visible marker to stderr when a response was served
+// from an expired cache entry because the API could not be reached.
+func reportStale(info *tempest.ResponseInfo) {
+	if info == nil || !info.Stale {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "[stale] showing cached data from %s ago, API unavailable: %v\n",
+		info.Age.Round(time.Second), info.Err)
+}
+
+// verboseLogf writes a diagnostic line to stderr when --verbose is set.
+func verboseLogf(format string, args ...any) {
+	if verbose {
+		fmt.Fprintf(os.Stderr, "[tempest-cli] "+format+"\n", args...)
+	}
+}
+
+// stationIDFlag returns the parsed value of the persistent station flag.
+func stationIDFlag(cmd *cobra.Command) (int, error) {
+	sid := cmd.Flag("station").Value.String()
+	if sid == "" {
+		return 0, fmt.Errorf("Station ID is required. Use -s <station_id>")
+	}
+	return tempest.ParseStationID(sid)
+}
@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"fmt"
+
+	"tempest-cli/tempest"
+
+	"github.com/spf13/cobra"
+)
+
+// cacheCmd groups commands that manage the on-disk response cache.
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the on-disk API response cache",
+	Long: `REST responses are cached under the user cache directory
+($XDG_CACHE_HOME/tempest-cli, usually ~/.cache/tempest-cli). Station
+metadata is kept for 24h, forecasts for 10m and observations for 1m. When
+the API cannot be reached, expired entries are served and marked as stale.`,
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Remove every cached API response",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir, err := tempest.DefaultCacheDir()
+		if err != nil {
+			return fmt.Errorf("locating cache directory: %w", err)
+		}
+		if err := tempest.NewFileCache(dir).Clear(); err != nil {
+			return fmt.Errorf("clearing cache: %w", err)
+		}
+		fmt.Println("Cleared cache at", dir)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(cacheCmd)
+	cacheCmd.AddCommand(cacheClearCmd)
+}
@@ -0,0 +1,334 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"tempest-cli/config"
+
+	"github.com/joho/godotenv"
+	"github.com/spf13/cobra"
+)
+
+var (
+	configPath string
+	apiToken   string
+)
+
+// configFlagSources maps a command line flag to the config key it falls back
+// to, and optionally how the config value translates into a flag value.
+var configFlagSources = []struct {
+	flag    string
+	key     string
+	flagVal func(string) (string, bool)
+}{
+	{flag: "temp-unit", key: "units.temp"},
+	{flag: "wind-unit", key: "units.wind"},
+	{flag: "pressure-unit", key: "units.pressure"},
+	{flag: "precip-unit", key: "units.precip"},
+	{flag: "distance-unit", key: "units.distance"},
+	{flag: "theme", key: "theme"},
+	{flag: "color", key: "color"},
+	{flag: "no-cache", key: "cache.enabled", flagVal: negateBool},
+	{flag: "max-age", key: "cache.max_age"},
+	{flag: "retries", key: "api.retries"},
+	{flag: "rate-limit", key: "api.rate_limit"},
+}
+
+func negateBool(v string) (string, bool) {
+	switch strings.ToLower(v) {
+	case "true":
+		return "false", true
+	case "false":
+		return "true", true
+	}
+	return "", false
+}
+
+// loadConfig reads the config file named by --config, or the default one.
+func loadConfig() (*config.Config, error) {
+	path := configPath
+	if path == "" {
+		var err error
+		path, err = config.DefaultPath()
+		if err != nil {
+			return nil, fmt.Errorf("locating config directory: %w", err)
+		}
+	}
+	return config.Load(path)
+}
+
+// applyConfig fills in every flag the user did not set on the command line.
+// Values are layered: flags, then environment variables (TEMPEST_TOKEN,
+// TEMPEST_STATION, TEMPEST_API_URL, TEMPEST_WS_URL), then the config file,
+// then a .env file in the working directory.
+func applyConfig(cmd *cobra.Command) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+
+	dotenv, err := godotenv.Read(".env")
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("reading .env: %w", err)
+	}
+
+	cfgStation, _ := cfg.Get("station")
+	if err := setFlagDefault(cmd, "station",
+		os.Getenv("TEMPEST_STATION"), cfgStation, dotenv["TEMPEST_STATION"]); err != nil {
+		return err
+	}
+
+	// A station alias selects a profile, whose settings take precedence over
+	// the environment and the rest of the config file.
+	var alias string
+	if f := cmd.Flags().Lookup("station"); f != nil {
+		id, a, err := resolveStation(cfg, f.Value.String())
+		if err != nil {
+			return err
+		}
+		if a != "" {
+			alias = a
+			if err := f.Value.Set(id); err != nil {
+				return err
+			}
+		}
+	}
+	profileValue := func(key string) string {
+		if alias == "" {
+			return ""
+		}
+		v, _ := cfg.Get(config.ProfileKey(alias, key))
+		return v
+	}
+
+	cfgToken, _ := cfg.Get("token")
+	if err := setFlagDefault(cmd, "token", profileValue("token"),
+		os.Getenv("TEMPEST_TOKEN"), cfgToken, dotenv["TEMPEST_TOKEN"], dotenv["API_TOKEN"]); err != nil {
+		return err
+	}
+
+	cfgAPIURL, _ := cfg.Get("api.url")
+	if err := setFlagDefault(cmd, "api-url", os.Getenv("TEMPEST_API_URL"), cfgAPIURL); err != nil {
+		return err
+	}
+	cfgWSURL, _ := cfg.Get("api.ws_url")
+	if err := setFlagDefault(cmd, "ws-url", os.Getenv("TEMPEST_WS_URL"), cfgWSURL); err != nil {
+		return err
+	}
+
+	for _, src := range configFlagSources {
+		v, ok := cfg.Get(src.key)
+		if pv := profileValue(src.key); pv != "" {
+			v, ok = pv, true
+		}
+		if !ok {
+			continue
+		}
+		if src.flagVal != nil {
+			translated, ok := src.flagVal(v)
+			if !ok {
+				return fmt.Errorf("config %s: invalid value %q", src.key, v)
+			}
+			v = translated
+		}
+		if err := setFlagDefault(cmd, src.flag, v); err != nil {
+			return fmt.Errorf("config %s: %w", src.key, err)
+		}
+	}
+	return nil
+}
+
+// resolveStation turns a station given as an ID or a profile alias into a
+// station ID. The alias is returned when s named a profile. Station lists
+// and group names are returned unchanged, for the live dashboard to expand
+// with dashboardStations.
+func resolveStation(cfg *config.Config, s string) (id, alias string, err error) {
+	if s == "" || !config.ValidAlias(s) {
+		return s, "", nil
+	}
+	if _, ok := cfg.Group(s); ok {
+		return s, "", nil
+	}
+	if !cfg.HasProfile(s) {
+		return "", "", fmt.Errorf("unknown station alias %q, add it with `tempest-cli profile add %s <station_id>`", s, s)
+	}
+	id, ok := cfg.Get(config.ProfileKey(s, "station"))
+	if !ok || id == "" {
+		return "", "", fmt.Errorf("profile %q has no station ID", s)
+	}
+	return id, s, nil
+}
+
+// setFlagDefault sets flag to the first non-empty candidate unless the user
+// gave it on the command line.
+func setFlagDefault(cmd *cobra.Command, name string, candidates ...string) error {
+	f := cmd.Flags().Lookup(name)
+	if f == nil || f.Changed {
+		return nil
+	}
+	for _, v := range candidates {
+		if v != "" {
+			return f.Value.Set(v)
+		}
+	}
+	return nil
+}
+
+// getAPIToken returns the token resolved from flags, environment, config
+// file or .env.
+func getAPIToken() (string, error) {
+	if apiToken == "" {
+		return "", fmt.Errorf("no API token configured; set one with `tempest-cli config set token <token>`, " +
+			"the TEMPEST_TOKEN environment variable or --token")
+	}
+	return apiToken, nil
+}
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Read and write the configuration file",
+	Long: `Manage the tempest-cli configuration file, stored at
+$XDG_CONFIG_HOME/tempest-cli/config.toml (config.yaml is also accepted).
+
+Settings are layered: command line flags override environment variables
+(TEMPEST_TOKEN, TEMPEST_STATION), which override the config file, which
+overrides a .env file in the working directory.`,
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "Print the value of a setting",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+		v, ok := cfg.Get(args[0])
+		if !ok {
+			return fmt.Errorf("%s is not set", args[0])
+		}
+		fmt.Println(v)
+		return nil
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Change a setting",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !config.IsKnown(args[0]) {
+			return fmt.Errorf("unknown setting %q, run `tempest-cli config list --all` to see valid keys", args[0])
+		}
+		if err := validateUnitSetting(args[0], args[1]); err != nil {
+			return err
+		}
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+		cfg.Set(args[0], args[1])
+		if strings.HasPrefix(args[0], "keys.") {
+			// Check the new keys against the others bound.
+			if _, err := loadKeyMap(cfg); err != nil {
+				return err
+			}
+		}
+		if err := cfg.Save(); err != nil {
+			return fmt.Errorf("saving config: %w", err)
+		}
+		return nil
+	},
+}
+
+var configListAll bool
+
+var configListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the settings in the configuration file",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+		entries := []configEntry{}
+		if configListAll {
+			for _, k := range config.Keys {
+				v, _ := cfg.Get(k.Name)
+				entries = append(entries, configEntry{k.Name, displayConfigValue(k.Name, v), k.Description})
+			}
+			for _, k := range config.KeyBindings {
+				key := config.KeyBindingKey(k.Name)
+				v, _ := cfg.Get(key)
+				entries = append(entries, configEntry{key, displayConfigValue(key, v), k.Description})
+			}
+		} else {
+			for _, k := range cfg.Keys() {
+				v, _ := cfg.Get(k)
+				entries = append(entries, configEntry{Key: k, Value: displayConfigValue(k, v)})
+			}
+		}
+		if outputFormat != nil {
+			return writeResult(entries, nil)
+		}
+
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, e := range entries {
+			if configListAll {
+				fmt.Fprintf(tw, "%s\t%s\t%s\n", e.Key, e.Value, e.Description)
+			} else {
+				fmt.Fprintf(tw, "%s\t%s\n", e.Key, e.Value)
+			}
+		}
+		return tw.Flush()
+	},
+}
+
+// configEntry is a row of `config list`.
+type configEntry struct {
+	Key         string `json:"key"`
+	Value       string `json:"value"`
+	Description string `json:"description,omitempty"`
+}
+
+// displayConfigValue masks secrets so listing the config is safe to share.
+func displayConfigValue(key, v string) string {
+	if v == "" {
+		return "-"
+	}
+	if key == "token" || strings.HasSuffix(key, ".token") {
+		if len(v) <= 4 {
+			return "****"
+		}
+		return "****" + v[len(v)-4:]
+	}
+	return v
+}
+
+var configPathCmd = &cobra.Command{
+	Use:   "path",
+	Short: "Print the location of the configuration file",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := configPath
+		if path == "" {
+			var err error
+			if path, err = config.DefaultPath(); err != nil {
+				return err
+			}
+		}
+		fmt.Println(path)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configGetCmd, configSetCmd, configListCmd, configPathCmd)
+
+	configListCmd.Flags().BoolVarP(&configListAll, "all", "a", false, "List every supported setting with its description")
+}
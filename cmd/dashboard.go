@@ -1,71 +1,177 @@
 package cmd
 
 import (
+	"cmp"
+	"context"
 	"encoding/json"
-	"fmt"
+	"io"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
+	"tempest-cli/tempest"
+	"tempest-cli/units"
+
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/gorilla/websocket"
 )
 
-// UnitPrefs holds user-selected unit preferences for the dashboard.
-type UnitPrefs struct {
-	TempF    bool
-	WindMph  bool
-	PrecipIn bool
-	DistMi   bool
-}
-
 // DashboardModel is the Bubbletea model for the live weather dashboard.
 type DashboardModel struct {
-	// Station metadata
-	stationName string
-	timezone    string
-	deviceID    int
-	apiToken    string
-
-	// Live data
-	currentObs  *ObsData
-	rapidWind   *RapidWindData
-	windHistory []RapidWindData
-	events      []EventData
+	// stations holds one state per device watched; active is the one shown
+	// in the tabbed view. grid shows every station as a compact tile instead.
+	stations []*stationState
+	active   int
+	grid     bool
+	apiToken string
+
+	// Data source: "ws" for the Tempest WebSocket API, "udp" for local hub
+	// broadcasts received on udpAddr, "replay" for a recorded session read
+	// from replay at replaySpeed.
+	source      string
+	udpAddr     string
+	replay      io.Reader
+	replaySpeed float64
+	replayDone  bool
+
+	// recorder, when set, saves every message received to a session file.
+	recorder *sessionRecorder
+
+	// offline holds the serial numbers, or IDs, of the devices and stations
+	// whose last online event said they went offline.
+	offline map[string]bool
 
 	// Connection state
 	connected         bool
-	lastUpdate        time.Time
 	errMsg            string
 	reconnecting      bool
 	reconnectAttempts int
-
-	// Display
-	width  int
-	height int
+	// reopening is set when the UDP socket was closed to reopen it.
+	reopening bool
+
+	// Display. layout is "auto" or the layout to use whatever the size.
+	// chart indexes chartMetrics; chartStyle is "braille" or "block".
+	// forecastScroll and eventScroll are the first hour and event shown;
+	// focus is the panel the scroll keys act on.
+	width          int
+	height         int
+	layout         string
+	chart          int
+	chartStyle     string
+	chartWindow    time.Duration
+	forecastScroll int
+	eventScroll    int
+	focus          dashPanel
+	help           bool
+
+	// keys maps keys to actions. While paused, data messages are held
+	// back, to be applied on resuming; see receive. notice is a message for the status
+	// bar, shown for a few seconds from noticeAt.
+	keys     keyMap
+	paused   bool
+	held     []tea.Msg
+	notice   string
+	noticeAt time.Time
 
 	// Preferences
-	unitPrefs UnitPrefs
+	units units.Prefs
+
+	// Source internals. Cancelling ctx stops the WebSocket client and the
+	// UDP read loop.
+	ws      *tempest.WSClient
+	udpConn net.PacketConn
+	ctx     context.Context
+	cancel  context.CancelFunc
+	msgCh   chan tea.Msg
+}
+
+// stationState is the live data of one device on the dashboard. Devices are
+// matched by ID over the WebSocket and by serial number in hub broadcasts;
+// either may be learnt from the first message that carries both.
+type stationState struct {
+	name      string
+	timezone  string
+	stationID int
+	deviceID  int
+	serial    string
+	// source labels where the data comes from when it is not a station of
+	// the account, such as the UDP address or the replayed file; the title
+	// shows it in place of the timezone.
+	source string
+
+	currentObs   *ObsData
+	obsHistory   []ObsData // the chart window, oldest first
+	rapidWind    *RapidWindData
+	windHistory  []RapidWindData
+	events       []EventData
+	deviceStatus *StatusData
+	lastUpdate   time.Time
+
+	// strikes holds every strike event of the session and strikeCounts
+	// every observation that saw lightning, oldest first.
+	strikes      []EventData
+	strikeCounts []lightningCount
+
+	// rain holds every observation of the session that collected rain,
+	// oldest first, and rainStarted the latest rain event.
+	rain        []rainSample
+	rainStarted time.Time
+
+	// forecast is the station's latest better_forecast, in metric units.
+	forecast *tempest.Forecast
+}
+
+// maxWindHistory and maxEvents bound how much of the session a station
+// keeps: enough rapid wind samples and events to fill a wide, tall terminal.
+const (
+	maxWindHistory = 300
+	maxEvents      = 50
+)
 
-	// WS internals
-	wsConn *websocket.Conn
-	wsDone chan struct{}
-	msgCh  chan tea.Msg
+// deviceRef identifies the device a message came from.
+type deviceRef struct {
+	id     int
+	serial string
 }
 
 // --- Tea message types ---
 
-type wsObsMsg struct{ obs ObsData }
-type wsRapidWindMsg struct{ wind RapidWindData }
+type wsObsMsg struct {
+	obs  ObsData
+	from deviceRef
+}
+type obsHistoryMsg struct {
+	obs  []ObsData
+	from deviceRef
+}
+type wsRapidWindMsg struct {
+	wind RapidWindData
+	from deviceRef
+}
+type forecastMsg struct {
+	stationID int
+	forecast  *tempest.Forecast
+}
+type forecastRefreshMsg struct{}
 type wsEventMsg struct{ event EventData }
-type wsConnectedMsg struct{ conn *websocket.Conn }
+type wsStatusMsg struct{ status StatusData }
+type wsStateMsg struct{ change tempest.WSStateChange }
+type udpListeningMsg struct{ conn net.PacketConn }
 type wsErrorMsg struct{ err error }
+type replayStartedMsg struct{}
+type replayDoneMsg struct{ err error }
 type wsReconnectMsg struct{}
 type tickMsg time.Time
 
-// Init starts the WS connection and background tickers.
+// Init starts the data source and background tickers.
 func (m DashboardModel) Init() tea.Cmd {
 	return tea.Batch(
-		m.connectWSCmd(),
+		m.connectCmd(),
 		m.waitForWSMsg(),
+		m.backfillCmd(),
+		m.forecastCmd(),
+		m.refreshForecastCmd(),
 		tickEvery(),
 	)
 }
@@ -75,65 +181,76 @@ func (m DashboardModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "q", "ctrl+c":
-			if m.wsConn != nil {
-				m.wsConn.Close()
-			}
-			if m.wsDone != nil {
-				select {
-				case <-m.wsDone:
-				default:
-					close(m.wsDone)
-				}
-			}
-			return m, tea.Quit
-		}
+		return m.handleKey(msg)
 
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
 		return m, nil
 
-	case wsConnectedMsg:
-		m.wsConn = msg.conn
+	case wsStateMsg:
+		switch msg.change.State {
+		case tempest.WSConnected:
+			m.connected = true
+			m.errMsg = ""
+			m.reconnecting = false
+			m.reconnectAttempts = 0
+		case tempest.WSReconnecting:
+			m.connected = false
+			m.reconnecting = true
+			m.reconnectAttempts = msg.change.Attempt
+			if msg.change.Err != nil {
+				m.errMsg = msg.change.Err.Error()
+			}
+		}
+		return m, m.waitForWSMsg()
+
+	case udpListeningMsg:
+		m.udpConn = msg.conn
 		m.connected = true
 		m.errMsg = ""
 		m.reconnecting = false
 		m.reconnectAttempts = 0
 		return m, nil
 
-	case wsObsMsg:
-		m.currentObs = &msg.obs
-		m.lastUpdate = time.Now()
-		m.errMsg = ""
-		return m, m.waitForWSMsg()
+	case replayStartedMsg:
+		m.connected = true
+		return m, nil
 
-	case wsRapidWindMsg:
-		m.rapidWind = &msg.wind
-		m.windHistory = append(m.windHistory, msg.wind)
-		if len(m.windHistory) > 20 {
-			m.windHistory = m.windHistory[len(m.windHistory)-20:]
+	case replayDoneMsg:
+		m.replayDone = true
+		if msg.err != nil {
+			m.errMsg = msg.err.Error()
 		}
-		m.lastUpdate = time.Now()
 		return m, m.waitForWSMsg()
 
-	case wsEventMsg:
-		m.events = append([]EventData{msg.event}, m.events...)
-		if len(m.events) > 10 {
-			m.events = m.events[:10]
-		}
-		m.lastUpdate = time.Now()
+	case wsObsMsg, wsRapidWindMsg, wsEventMsg, wsStatusMsg:
+		m.receive(msg)
 		return m, m.waitForWSMsg()
 
+	case obsHistoryMsg, forecastMsg:
+		m.receive(msg)
+		return m, nil
+
+	case forecastRefreshMsg:
+		return m, tea.Batch(m.forecastCmd(), m.refreshForecastCmd())
+
 	case wsErrorMsg:
+		if m.reopening {
+			// The read loop reporting the socket closed by reconnect.
+			m.reopening = false
+			m.udpConn = nil
+			return m, tea.Batch(m.waitForWSMsg(), m.connectCmd())
+		}
 		m.connected = false
 		m.errMsg = msg.err.Error()
-		if m.wsConn != nil {
-			m.wsConn.Close()
-			m.wsConn = nil
+		if m.udpConn != nil {
+			m.udpConn.Close()
+			m.udpConn = nil
 		}
-		if m.reconnectAttempts < 5 {
+		// The WebSocket client reconnects by itself and only gives up on
+		// errors a retry cannot fix; the UDP socket is reopened here.
+		if m.source == "udp" && m.reconnectAttempts < 5 {
 			m.reconnecting = true
 			m.reconnectAttempts++
 			return m, tea.Batch(
@@ -147,7 +264,7 @@ func (m DashboardModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case wsReconnectMsg:
 		if !m.connected && m.reconnecting {
 			return m, tea.Batch(
-				m.connectWSCmd(),
+				m.connectCmd(),
 				m.waitForWSMsg(),
 			)
 		}
@@ -160,142 +277,576 @@ func (m DashboardModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// handleKey runs the action bound to the key pressed. While the help is
+// shown, any key but quit closes it.
+func (m DashboardModel) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	key := msg.String()
+	action, ok := m.keys.action(key)
+	if m.help && action != actQuit {
+		m.help = false
+		return m, nil
+	}
+	if !ok {
+		if len(key) == 1 && key[0] >= '1' && key[0] <= '9' {
+			if i := int(key[0] - '1'); i < len(m.stations) {
+				m.showStation(i)
+			}
+		}
+		return m, nil
+	}
+
+	switch action {
+	case actQuit:
+		m.cancel()
+		if m.udpConn != nil {
+			m.udpConn.Close()
+		}
+		return m, tea.Quit
+	case actHelp:
+		m.help = true
+	case actNextStation:
+		m.showStation((m.active + 1) % len(m.stations))
+	case actPrevStation:
+		m.showStation((m.active + len(m.stations) - 1) % len(m.stations))
+	case actGrid:
+		m.grid = !m.grid && len(m.stations) > 1
+	case actTabs:
+		m.grid = false
+	case actFocus:
+		m.focus = m.nextFocus()
+	case actForward:
+		m.scroll(1)
+	case actBack:
+		m.scroll(-1)
+	case actChart:
+		m.chart = (m.chart + 1) % len(chartMetrics)
+	case actTempUnit:
+		m.units.Temp = nextUnit(tempUnitCycle, m.units.Temp)
+		m.setNotice("Temperature in " + m.units.Temp.Symbol())
+	case actWindUnit:
+		m.units.Wind = nextUnit(windUnitCycle, m.units.Wind)
+		m.setNotice("Wind in " + m.units.Wind.Symbol())
+	case actPrecipUnit:
+		m.units.Precip = nextUnit(precipUnitCycle, m.units.Precip)
+		m.setNotice("Precipitation in " + m.units.Precip.Symbol())
+	case actPause:
+		m.paused = !m.paused
+		if !m.paused {
+			for _, held := range m.held {
+				m.apply(held)
+			}
+			m.held = nil
+		}
+	case actReconnect:
+		return m.reconnect()
+	case actClearEvents:
+		for i, st := range m.stations {
+			if m.grid || i == m.active {
+				st.events = nil
+			}
+		}
+		m.eventScroll = 0
+	}
+	return m, nil
+}
+
+// showStation switches the tabbed view to station i.
+func (m *DashboardModel) showStation(i int) {
+	if i != m.active {
+		m.forecastScroll, m.eventScroll = 0, 0
+	}
+	m.active = i
+}
+
+// scroll moves the focused panel by n: the chart to another metric, the
+// events to older or newer ones and otherwise the forecast strip by hours.
+func (m *DashboardModel) scroll(n int) {
+	switch m.focus {
+	case panelChart:
+		m.chart = (m.chart + n + len(chartMetrics)) % len(chartMetrics)
+	case panelEvents:
+		m.eventScroll = min(max(0, len(m.station().events)-1), max(0, m.eventScroll+n))
+	default:
+		m.scrollForecast(n)
+	}
+}
+
+// noticeDuration is how long a notice stays in the status bar.
+const noticeDuration = 3 * time.Second
+
+func (m *DashboardModel) setNotice(notice string) {
+	m.notice = notice
+	m.noticeAt = time.Now()
+}
+
+// reconnect drops the connection to the data source and connects again
+// straight away. A replay has nothing to reconnect to.
+func (m DashboardModel) reconnect() (tea.Model, tea.Cmd) {
+	switch m.source {
+	case "ws":
+		m.ws.Reconnect()
+		m.setNotice("Reconnecting")
+	case "udp":
+		m.reconnecting = true
+		m.reconnectAttempts = 0
+		m.setNotice("Reopening the UDP socket")
+		if m.udpConn != nil {
+			// The read loop reports the closed socket, and the error
+			// handler reopens it.
+			m.reopening = true
+			m.udpConn.Close()
+			return m, nil
+		}
+		return m, m.connectCmd()
+	}
+	return m, nil
+}
+
+// receive applies a data message, or holds it back while paused. Of the
+// observations and rapid wind readings, only each device's latest is held,
+// so a long pause holds little more than the events.
+func (m *DashboardModel) receive(msg tea.Msg) {
+	if !m.paused {
+		m.apply(msg)
+		return
+	}
+	if i := slices.IndexFunc(m.held, func(held tea.Msg) bool { return supersedes(msg, held) }); i >= 0 {
+		m.held = slices.Delete(m.held, i, i+1)
+	}
+	m.held = append(m.held, msg)
+}
+
+// supersedes reports whether msg replaces the held message: both are an
+// observation, or both a rapid wind reading, from the same device.
+func supersedes(msg, held tea.Msg) bool {
+	switch msg := msg.(type) {
+	case wsObsMsg:
+		h, ok := held.(wsObsMsg)
+		return ok && h.from == msg.from
+	case wsRapidWindMsg:
+		h, ok := held.(wsRapidWindMsg)
+		return ok && h.from == msg.from
+	}
+	return false
+}
+
+// apply updates the stations with a data message.
+func (m *DashboardModel) apply(msg tea.Msg) {
+	switch msg := msg.(type) {
+	case wsObsMsg:
+		if st := m.stationFor(msg.from); st != nil {
+			st.currentObs = &msg.obs
+			st.addObs(m.chartWindowOrDefault(), msg.obs)
+			st.addLightningCounts(msg.obs)
+			st.addRainObs(msg.obs)
+			st.lastUpdate = time.Now()
+		}
+		m.errMsg = ""
+
+	case obsHistoryMsg:
+		if st := m.stationFor(msg.from); st != nil {
+			st.addObs(m.chartWindowOrDefault(), msg.obs...)
+			st.addLightningCounts(msg.obs...)
+			st.addRainObs(msg.obs...)
+		}
+
+	case forecastMsg:
+		for _, st := range m.stations {
+			if st.stationID == msg.stationID {
+				st.forecast = msg.forecast
+			}
+		}
+
+	case wsRapidWindMsg:
+		if st := m.stationFor(msg.from); st != nil {
+			st.rapidWind = &msg.wind
+			st.windHistory = append(st.windHistory, msg.wind)
+			if len(st.windHistory) > maxWindHistory {
+				st.windHistory = st.windHistory[len(st.windHistory)-maxWindHistory:]
+			}
+			st.lastUpdate = time.Now()
+		}
+
+	case wsEventMsg:
+		m.trackOnline(msg.event)
+		for _, st := range m.eventStations(msg.event) {
+			st.addEvent(msg.event)
+			switch {
+			case msg.event.Type == EventLightning:
+				st.addStrike(msg.event)
+			case msg.event.Type == EventRain && msg.event.Timestamp.After(st.rainStarted):
+				st.rainStarted = msg.event.Timestamp
+			}
+			st.lastUpdate = time.Now()
+		}
+
+	case wsStatusMsg:
+		if msg.status.Kind == "device" {
+			if st := m.stationFor(deviceRef{serial: msg.status.SerialNumber}); st != nil {
+				st.recordSensorFaults(msg.status)
+				st.deviceStatus = &msg.status
+			}
+		}
+	}
+}
+
 // View delegates to the dashboard renderer.
 func (m DashboardModel) View() string {
 	return renderDashboard(m)
 }
 
-// --- WS command functions ---
+// station returns the station shown in the tabbed view.
+func (m DashboardModel) station() *stationState {
+	return m.stations[m.active]
+}
 
-func (m DashboardModel) connectWSCmd() tea.Cmd {
-	return func() tea.Msg {
-		wsURL := fmt.Sprintf("wss://ws.weatherflow.com/swd/data?token=%s", m.apiToken)
-		conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
-		if err != nil {
-			return wsErrorMsg{err: fmt.Errorf("ws dial: %w", err)}
+// lastUpdate returns when the shown station, or in the grid any station,
+// last received data.
+func (m DashboardModel) lastUpdate() time.Time {
+	last := m.station().lastUpdate
+	if m.grid {
+		for _, st := range m.stations {
+			if st.lastUpdate.After(last) {
+				last = st.lastUpdate
+			}
 		}
+	}
+	return last
+}
 
-		// Send listen_start
-		listenStart := WSListenStart{
-			Type:     "listen_start",
-			DeviceID: m.deviceID,
-			ID:       "tempest-cli-obs",
+// clock returns the time the dashboard shows data as of: now, or in a
+// replay the time of the latest observation or event.
+func (m DashboardModel) clock() time.Time {
+	if m.source != "replay" {
+		return time.Now()
+	}
+	var last time.Time
+	for _, st := range m.stations {
+		if st.currentObs != nil && st.currentObs.Timestamp.After(last) {
+			last = st.currentObs.Timestamp
+		}
+		if len(st.events) > 0 && st.events[0].Timestamp.After(last) {
+			last = st.events[0].Timestamp
 		}
-		if err := conn.WriteJSON(listenStart); err != nil {
-			conn.Close()
-			return wsErrorMsg{err: fmt.Errorf("ws listen_start: %w", err)}
+	}
+	return last
+}
+
+// stationFor returns the state of the device a message came from, or nil
+// when it is not one the dashboard watches. The WebSocket only delivers the
+// devices subscribed to; hub broadcasts and replays may carry any device,
+// so there the first one seen takes over the initial state and any other
+// gets a state of its own.
+func (m *DashboardModel) stationFor(from deviceRef) *stationState {
+	for _, st := range m.stations {
+		if from.id != 0 && st.deviceID == from.id || from.serial != "" && st.serial == from.serial {
+			st.deviceID = cmp.Or(st.deviceID, from.id)
+			st.serial = cmp.Or(st.serial, from.serial)
+			return st
 		}
+	}
+	if m.source == "ws" {
+		return nil
+	}
+	first := m.stations[0]
+	if from == (deviceRef{}) {
+		return first
+	}
+	if len(m.stations) == 1 && first.deviceID == 0 && first.serial == "" {
+		first.deviceID, first.serial = from.id, from.serial
+		return first
+	}
+	name := from.serial
+	if name == "" {
+		name = "Device " + strconv.Itoa(from.id)
+	}
+	st := &stationState{name: name, timezone: first.timezone, source: first.source, deviceID: from.id, serial: from.serial}
+	m.stations = append(m.stations, st)
+	return st
+}
 
-		// Send listen_rapid_start
-		listenRapid := WSListenRapidStart{
-			Type:     "listen_rapid_start",
-			DeviceID: m.deviceID,
-			ID:       "tempest-cli-rapid",
+// eventStations returns the states an event belongs to: the device's, or
+// for station events every device of the station.
+func (m *DashboardModel) eventStations(ev EventData) []*stationState {
+	if ev.DeviceID != 0 || ev.SerialNumber != "" {
+		if st := m.stationFor(deviceRef{ev.DeviceID, ev.SerialNumber}); st != nil {
+			return []*stationState{st}
 		}
-		if err := conn.WriteJSON(listenRapid); err != nil {
-			conn.Close()
-			return wsErrorMsg{err: fmt.Errorf("ws listen_rapid_start: %w", err)}
+		return nil
+	}
+	var out []*stationState
+	for _, st := range m.stations {
+		if st.stationID == ev.StationID {
+			out = append(out, st)
 		}
+	}
+	if len(out) == 0 && m.source != "ws" {
+		out = m.stations[:1]
+	}
+	return out
+}
 
-		// Start the read loop goroutine
-		go wsReadLoop(conn, m.msgCh, m.wsDone)
+// addEvent adds ev to the top of the station's recent events.
+func (st *stationState) addEvent(ev EventData) {
+	st.events = append([]EventData{ev}, st.events...)
+	if len(st.events) > maxEvents {
+		st.events = st.events[:maxEvents]
+	}
+}
 
-		return wsConnectedMsg{conn: conn}
+// recordSensorFaults adds an event when the sensors a device reports as
+// failed change, so a failure is noticed without watching the status bar.
+func (st *stationState) recordSensorFaults(status StatusData) {
+	prev := 0
+	if st.deviceStatus != nil {
+		prev = st.deviceStatus.SensorStatus
 	}
+	if status.SensorStatus == prev {
+		return
+	}
+	ev := EventData{
+		Timestamp:    status.Timestamp,
+		Type:         EventSensor,
+		Severity:     SeverityInfo,
+		SerialNumber: status.SerialNumber,
+		Detail:       "Sensors OK",
+	}
+	if faults := status.SensorFaults(); len(faults) > 0 {
+		ev.Severity = SeverityWarning
+		ev.Detail = "Sensor failure: " + strings.Join(faults, ", ")
+	}
+	st.addEvent(ev)
 }
 
-func wsReadLoop(conn *websocket.Conn, msgCh chan<- tea.Msg, done chan struct{}) {
-	defer func() {
-		select {
-		case <-done:
-		default:
-		}
-	}()
+// isOffline reports whether the last online event for the station or its
+// device said it went offline.
+func (m DashboardModel) isOffline(st *stationState) bool {
+	return m.offline["device "+st.serial] ||
+		m.offline["device "+strconv.Itoa(st.deviceID)] ||
+		m.offline["station "+strconv.Itoa(st.stationID)]
+}
 
-	for {
-		select {
-		case <-done:
-			return
-		default:
+// trackOnline records device and station offline/online transitions.
+func (m *DashboardModel) trackOnline(ev EventData) {
+	var key string
+	switch ev.Type {
+	case EventDeviceOnline, EventDeviceOffline:
+		key = "device " + ev.SerialNumber
+		if ev.SerialNumber == "" {
+			key = "device " + strconv.Itoa(ev.DeviceID)
 		}
+	case EventStationOnline, EventStationOffline:
+		key = "station " + strconv.Itoa(ev.StationID)
+	default:
+		return
+	}
+	if m.offline == nil {
+		m.offline = map[string]bool{}
+	}
+	if strings.HasSuffix(ev.Type, "_offline") {
+		m.offline[key] = true
+	} else {
+		delete(m.offline, key)
+	}
+}
 
-		// Set read deadline to detect stale connections (11 min > obs_st ~60s interval)
-		conn.SetReadDeadline(time.Now().Add(11 * time.Minute))
+// --- WS command functions ---
 
-		_, raw, err := conn.ReadMessage()
+// connectCmd connects to the configured data source.
+func (m DashboardModel) connectCmd() tea.Cmd {
+	switch m.source {
+	case "udp":
+		return m.listenUDPCmd()
+	case "replay":
+		return m.replayCmd()
+	}
+	return m.connectWSCmd()
+}
+
+// decoder returns the function live messages are decoded with, recording
+// them first when a recording was asked for.
+func (m DashboardModel) decoder() func([]byte) tea.Msg {
+	if m.recorder != nil {
+		return m.recorder.wrap(decodeMessage)
+	}
+	return decodeMessage
+}
+
+func (m DashboardModel) listenUDPCmd() tea.Cmd {
+	return func() tea.Msg {
+		conn, err := listenUDP(m.udpAddr)
 		if err != nil {
-			select {
-			case <-done:
-				return
-			default:
-				msgCh <- wsErrorMsg{err: fmt.Errorf("ws read: %w", err)}
-				return
-			}
+			return wsErrorMsg{err: err}
 		}
+		go udpReadLoop(conn, m.msgCh, m.ctx.Done(), m.decoder())
+		return udpListeningMsg{conn: conn}
+	}
+}
 
-		var envelope WSMessage
-		if err := json.Unmarshal(raw, &envelope); err != nil {
-			continue
-		}
+func (m DashboardModel) connectWSCmd() tea.Cmd {
+	return func() tea.Msg {
+		runWSClient(m.ctx, m.ws, m.msgCh, m.decoder())
+		return nil
+	}
+}
 
-		switch envelope.Type {
-		case "obs_st":
-			var obs WSObservation
-			if err := json.Unmarshal(raw, &obs); err != nil {
-				continue
+// backfillCmd fetches the chart window of observations for every station
+// from the REST API, so the charts start full rather than empty. Only the
+// WebSocket source knows its device IDs up front. A station whose history
+// cannot be fetched just charts what arrives live.
+func (m DashboardModel) backfillCmd() tea.Cmd {
+	if m.source != "ws" {
+		return nil
+	}
+	client := newAPIClientWithToken(m.apiToken)
+	end := time.Now()
+	start := end.Add(-m.chartWindowOrDefault())
+
+	var cmds []tea.Cmd
+	for _, st := range m.stations {
+		from := deviceRef{id: st.deviceID}
+		cmds = append(cmds, func() tea.Msg {
+			d, err := client.GetDeviceObservations(m.ctx, from.id, start, end)
+			if err != nil {
+				return nil
 			}
-			if len(obs.Obs) > 0 {
-				parsed := ParseObsArray(obs.Obs[0])
-				msgCh <- wsObsMsg{obs: parsed}
+			obs := make([]ObsData, 0, len(d.Obs))
+			for _, row := range d.Obs {
+				obs = append(obs, ParseObsArray(row))
 			}
+			return obsHistoryMsg{obs: obs, from: from}
+		})
+	}
+	return tea.Batch(cmds...)
+}
 
-		case "rapid_wind":
-			var rw WSRapidWind
-			if err := json.Unmarshal(raw, &rw); err != nil {
-				continue
-			}
-			if len(rw.Ob) >= 3 {
-				parsed := ParseRapidWind(rw.Ob)
-				msgCh <- wsRapidWindMsg{wind: parsed}
+// forecastCmd fetches the forecast of every station for the forecast strip.
+// Like the backfill it needs station IDs, so only the WebSocket source has
+// a forecast. A failed fetch keeps the forecast already shown until the
+// next refresh.
+func (m DashboardModel) forecastCmd() tea.Cmd {
+	if m.source != "ws" {
+		return nil
+	}
+	client := newAPIClientWithToken(m.apiToken)
+	// Fetch metric and convert locally, as the forecast command does.
+	metric := tempest.ForecastUnitOptions{Temp: "c", Wind: "mps", Pressure: "mb", Precip: "mm", Distance: "km"}
+
+	var cmds []tea.Cmd
+	seen := map[int]bool{}
+	for _, st := range m.stations {
+		sid := st.stationID
+		if seen[sid] {
+			continue
+		}
+		seen[sid] = true
+		cmds = append(cmds, func() tea.Msg {
+			f, err := client.GetBetterForecast(m.ctx, sid, metric)
+			if err != nil {
+				return nil
 			}
+			return forecastMsg{stationID: sid, forecast: f}
+		})
+	}
+	return tea.Batch(cmds...)
+}
 
-		case "evt_precip":
-			var ep WSEventPrecip
-			if err := json.Unmarshal(raw, &ep); err != nil {
-				continue
-			}
-			ts := time.Now()
-			if len(ep.Evt) > 0 {
-				ts = time.Unix(int64(ep.Evt[0]), 0)
-			}
-			msgCh <- wsEventMsg{event: EventData{
-				Timestamp: ts,
-				Type:      "rain",
-				Detail:    "Rain started",
-			}}
-
-		case "evt_strike":
-			var es WSEventStrike
-			if err := json.Unmarshal(raw, &es); err != nil {
-				continue
-			}
-			ts := time.Now()
-			detail := "Lightning detected"
-			if len(es.Evt) >= 3 {
-				ts = time.Unix(int64(es.Evt[0]), 0)
-				dist := es.Evt[1]
-				detail = fmt.Sprintf("Lightning %.0fkm away", dist)
+// refreshForecastCmd schedules the next forecast fetch.
+func (m DashboardModel) refreshForecastCmd() tea.Cmd {
+	if m.source != "ws" {
+		return nil
+	}
+	return tea.Tick(forecastRefresh, func(time.Time) tea.Msg {
+		return forecastRefreshMsg{}
+	})
+}
+
+// replayCmd feeds the recorded session through the same decoding as live
+// messages.
+func (m DashboardModel) replayCmd() tea.Cmd {
+	return func() tea.Msg {
+		go func() {
+			err := replaySession(m.ctx, m.replay, m.replaySpeed, m.msgCh, decodeMessage)
+			if m.ctx.Err() == nil {
+				deliver(m.msgCh, m.ctx.Done(), replayDoneMsg{err: err})
 			}
-			msgCh <- wsEventMsg{event: EventData{
-				Timestamp: ts,
-				Type:      "lightning",
-				Detail:    detail,
-			}}
+		}()
+		return replayStartedMsg{}
+	}
+}
+
+// decodeMessage turns a Tempest JSON message, received over the WebSocket or
+// as a UDP broadcast, into a tea message. It returns nil for messages that
+// are malformed or of no interest.
+func decodeMessage(raw []byte) tea.Msg {
+	var envelope WSMessage
+	if err := json.Unmarshal(raw, &envelope); err != nil {
+		return nil
+	}
+
+	switch envelope.Type {
+	case "obs_st":
+		var obs WSObservation
+		if err := json.Unmarshal(raw, &obs); err != nil {
+			return nil
+		}
+		if len(obs.Obs) > 0 {
+			o := ParseObsArray(obs.Obs[0])
+			o.Summary = obs.Summary
+			return wsObsMsg{obs: o, from: deviceRef{obs.DeviceID, obs.SerialNumber}}
+		}
 
-		case "ack":
-			// Acknowledged, nothing to do
+	case "rapid_wind":
+		var rw WSRapidWind
+		if err := json.Unmarshal(raw, &rw); err != nil {
+			return nil
+		}
+		if len(rw.Ob) >= 3 {
+			return wsRapidWindMsg{wind: ParseRapidWind(rw.Ob), from: deviceRef{rw.DeviceID, rw.SerialNumber}}
 		}
+
+	case "evt_precip":
+		var ep WSEventPrecip
+		if err := json.Unmarshal(raw, &ep); err != nil {
+			return nil
+		}
+		return wsEventMsg{event: ep.Parse()}
+
+	case "evt_strike":
+		var es WSEventStrike
+		if err := json.Unmarshal(raw, &es); err != nil {
+			return nil
+		}
+		// A strike without its distance would show as one at 0 km.
+		if len(es.Evt) >= 3 {
+			return wsEventMsg{event: es.Parse()}
+		}
+
+	case "evt_device_online", "evt_device_offline", "evt_station_online", "evt_station_offline":
+		var eo WSEventOnline
+		if err := json.Unmarshal(raw, &eo); err != nil {
+			return nil
+		}
+		return wsEventMsg{event: eo.Parse()}
+
+	case "device_status":
+		var ds UDPDeviceStatus
+		if err := json.Unmarshal(raw, &ds); err != nil {
+			return nil
+		}
+		return wsStatusMsg{status: ds.Parse()}
+
+	case "hub_status":
+		var hs UDPHubStatus
+		if err := json.Unmarshal(raw, &hs); err != nil {
+			return nil
+		}
+		return wsStatusMsg{status: hs.Parse()}
+
+	case "ack":
+		// Acknowledged, nothing to do
 	}
+	return nil
 }
 
 func (m DashboardModel) waitForWSMsg() tea.Cmd {
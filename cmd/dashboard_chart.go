@@ -0,0 +1,322 @@
+package cmd
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"tempest-cli/units"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Bounds and default of --chart-window, the span of observations each
+// station keeps for its charts.
+const (
+	minChartWindow     = time.Hour
+	maxChartWindow     = 24 * time.Hour
+	defaultChartWindow = 3 * time.Hour
+)
+
+// chartMetric is a measurement the trends panel can chart. value returns it
+// in metric units; format renders a metric value in the display units.
+type chartMetric struct {
+	name   string
+	value  func(ObsData) float64
+	format func(units.Prefs, float64) string
+}
+
+// chartMetrics are the metrics the chart key cycles through, in order.
+var chartMetrics = []chartMetric{
+	{"Temperature", func(o ObsData) float64 { return o.Temperature },
+		func(p units.Prefs, v float64) string { return p.FormatTemp(units.Temperature(v)) }},
+	{"Humidity", func(o ObsData) float64 { return o.Humidity },
+		func(p units.Prefs, v float64) string { return fmt.Sprintf("%.0f%%", v) }},
+	{"Pressure", func(o ObsData) float64 { return o.Pressure },
+		func(p units.Prefs, v float64) string { return p.FormatPressure(units.Pressure(v)) }},
+	{"Wind", func(o ObsData) float64 { return o.WindAvg },
+		func(p units.Prefs, v float64) string { return p.FormatSpeed(units.Speed(v)) }},
+	{"Solar radiation", func(o ObsData) float64 { return o.SolarRadiation },
+		func(p units.Prefs, v float64) string { return fmt.Sprintf("%.0f W/m²", v) }},
+}
+
+// addObs merges obs into the station's observation history, which is kept
+// in time order with one observation per timestamp, and drops observations
+// more than window older than the newest.
+func (st *stationState) addObs(window time.Duration, obs ...ObsData) {
+	h := append(st.obsHistory, obs...)
+	sort.SliceStable(h, func(i, j int) bool { return h[i].Timestamp.Before(h[j].Timestamp) })
+
+	// Later additions replace earlier ones with the same timestamp.
+	out := h[:0]
+	for _, o := range h {
+		if n := len(out); n > 0 && out[n-1].Timestamp.Equal(o.Timestamp) {
+			out[n-1] = o
+			continue
+		}
+		out = append(out, o)
+	}
+	if n := len(out); n > 0 {
+		cutoff := out[n-1].Timestamp.Add(-window)
+		i := sort.Search(n, func(i int) bool { return out[i].Timestamp.After(cutoff) })
+		out = out[i:]
+	}
+	st.obsHistory = out
+}
+
+// chartPoint is a value at a time.
+type chartPoint struct {
+	t time.Time
+	v float64
+}
+
+// chartPoints returns the metric's values over the station's history, and
+// their range.
+func chartPoints(history []ObsData, metric chartMetric) (points []chartPoint, lo, hi float64) {
+	lo, hi = math.Inf(1), math.Inf(-1)
+	for _, o := range history {
+		v := metric.value(o)
+		points = append(points, chartPoint{o.Timestamp, v})
+		lo, hi = math.Min(lo, v), math.Max(hi, v)
+	}
+	return points, lo, hi
+}
+
+// renderChartPanel renders the trends panel, height rows tall with its
+// border, charting the selected metric over the chart window.
+func renderChartPanel(m DashboardModel, width, height int) string {
+	st := m.station()
+	theme := getWeatherTheme(inferWeatherIcon(st.currentObs))
+	metric := chartMetrics[m.chart%len(chartMetrics)]
+	window := m.chartWindowOrDefault()
+
+	panelStyle := lipgloss.NewStyle().
+		Width(width - 2).
+		Height(height - 2).
+		BorderStyle(m.border(panelChart)).
+		BorderForeground(m.borderColor(panelChart, theme)).
+		Padding(0, 2)
+	headerStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Text)).Bold(true)
+	labelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Label))
+	valueStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Text))
+	lineStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Primary))
+
+	header := headerStyle.Render(fmt.Sprintf("  %s, last %s", strings.ToUpper(metric.name), shortDuration(window)))
+	points, lo, hi := chartPoints(st.obsHistory, metric)
+	if len(points) == 0 {
+		return panelStyle.Render(header + "\n" + labelStyle.Render("  Waiting for observation data..."))
+	}
+	last := points[len(points)-1]
+	header += "   " + labelStyle.Render("now ") + valueStyle.Render(metric.format(m.units, last.v)) +
+		"  " + labelStyle.Render("min ") + valueStyle.Render(metric.format(m.units, lo)) +
+		"  " + labelStyle.Render("max ") + valueStyle.Render(metric.format(m.units, hi))
+
+	// The y axis is labelled with the range; the x axis spans the window up
+	// to the newest observation, so replays chart the recorded times.
+	hiLabel, loLabel := metric.format(m.units, hi), metric.format(m.units, lo)
+	axisWidth := max(lipgloss.Width(hiLabel), lipgloss.Width(loLabel))
+	rows := max(1, height-2-1-2) // border, header, x axis and its labels
+	cols := max(1, width-2-4-axisWidth-2)
+	end := last.t
+	start := end.Add(-window)
+
+	var plot []string
+	if m.chartStyle == "block" {
+		plot = blockChart(points, start, end, lo, hi, cols, rows)
+	} else {
+		plot = brailleChart(points, start, end, lo, hi, cols, rows)
+	}
+
+	lines := []string{header}
+	for i, row := range plot {
+		label, tick := "", "│"
+		switch i {
+		case 0:
+			label, tick = hiLabel, "┤"
+		case len(plot) - 1:
+			label, tick = loLabel, "┤"
+		}
+		lines = append(lines, labelStyle.Render(fmt.Sprintf("%*s %s", axisWidth, label, tick))+lineStyle.Render(row))
+	}
+	lines = append(lines,
+		labelStyle.Render(strings.Repeat(" ", axisWidth+1)+"└"+strings.Repeat("─", cols)),
+		labelStyle.Render(strings.Repeat(" ", axisWidth+2)+timeAxisLabels(start, end, cols)),
+	)
+	return panelStyle.Render(strings.Join(lines, "\n"))
+}
+
+// chartWindowOrDefault returns the chart window, defaulting when unset.
+func (m DashboardModel) chartWindowOrDefault() time.Duration {
+	if m.chartWindow <= 0 {
+		return defaultChartWindow
+	}
+	return m.chartWindow
+}
+
+// timeAxisLabels spreads the clock times of start, the middle and end over
+// width columns.
+func timeAxisLabels(start, end time.Time, width int) string {
+	left := start.Format("15:04")
+	mid := start.Add(end.Sub(start) / 2).Format("15:04")
+	right := end.Format("15:04")
+	if width < len(left)+len(mid)+len(right)+2 {
+		return left + strings.Repeat(" ", max(1, width-len(left)-len(right))) + right
+	}
+	gap1 := width/2 - len(left) - len(mid)/2
+	gap2 := width - len(left) - gap1 - len(mid) - len(right)
+	return left + strings.Repeat(" ", gap1) + mid + strings.Repeat(" ", max(1, gap2)) + right
+}
+
+// shortDuration formats d without zero units, e.g. 3h, 1h30m or 45m.
+func shortDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	h, m := int(d.Hours()), int(d.Minutes())%60
+	switch {
+	case h == 0:
+		return fmt.Sprintf("%dm", m)
+	case m == 0:
+		return fmt.Sprintf("%dh", h)
+	}
+	return fmt.Sprintf("%dh%dm", h, m)
+}
+
+// scaleChart maps v within [lo, hi] to 0..steps-1. A flat range is drawn
+// in the middle.
+func scaleChart(v, lo, hi float64, steps int) int {
+	if hi-lo < 1e-9 {
+		return steps / 2
+	}
+	return min(steps-1, max(0, int(math.Round((v-lo)/(hi-lo)*float64(steps-1)))))
+}
+
+// binChart averages points into n columns spanning start to end. Columns
+// without data are NaN.
+func binChart(points []chartPoint, start, end time.Time, n int) []float64 {
+	sums := make([]float64, n)
+	counts := make([]int, n)
+	span := end.Sub(start)
+	for _, p := range points {
+		if p.t.Before(start) || p.t.After(end) || span <= 0 {
+			continue
+		}
+		x := min(n-1, int(float64(p.t.Sub(start))/float64(span)*float64(n)))
+		sums[x] += p.v
+		counts[x]++
+	}
+	for i := range sums {
+		if counts[i] == 0 {
+			sums[i] = math.NaN()
+		} else {
+			sums[i] /= float64(counts[i])
+		}
+	}
+	return sums
+}
+
+// brailleChart draws points as a line in a cols by rows grid of braille
+// characters, each two dots wide and four high. Gaps in the data of more
+// than a few columns are left open.
+func brailleChart(points []chartPoint, start, end time.Time, lo, hi float64, cols, rows int) []string {
+	w, h := cols*2, rows*4
+	dots := make([][]bool, h)
+	for y := range dots {
+		dots[y] = make([]bool, w)
+	}
+	set := func(x, y int) { dots[h-1-y][x] = true }
+
+	prevX, prevY := -1, 0
+	for x, v := range binChart(points, start, end, w) {
+		if math.IsNaN(v) {
+			continue
+		}
+		y := scaleChart(v, lo, hi, h)
+		if prevX >= 0 && x-prevX <= 6 {
+			// Join to the previous point, stepping one dot at a time.
+			steps := max(x-prevX, abs(y-prevY))
+			for i := 1; i <= steps; i++ {
+				set(prevX+(x-prevX)*i/steps, prevY+(y-prevY)*i/steps)
+			}
+		} else {
+			set(x, y)
+		}
+		prevX, prevY = x, y
+	}
+	return brailleRows(dots, cols, rows)
+}
+
+// brailleRows renders a grid of dots, four rows and two columns of it per
+// character, as rows lines of cols braille characters.
+func brailleRows(dots [][]bool, cols, rows int) []string {
+	// Braille dot bits, indexed by row within the cell and column.
+	bits := [4][2]rune{{0x01, 0x08}, {0x02, 0x10}, {0x04, 0x20}, {0x40, 0x80}}
+	lines := make([]string, rows)
+	for r := range rows {
+		var sb strings.Builder
+		for c := range cols {
+			var cell rune
+			for dy := range 4 {
+				for dx := range 2 {
+					if dots[r*4+dy][c*2+dx] {
+						cell |= bits[dy][dx]
+					}
+				}
+			}
+			if cell == 0 {
+				sb.WriteRune(' ')
+			} else {
+				sb.WriteRune(0x2800 + cell)
+			}
+		}
+		lines[r] = sb.String()
+	}
+	return lines
+}
+
+// blockChart draws points as columns of block characters, for terminals
+// whose font lacks braille.
+func blockChart(points []chartPoint, start, end time.Time, lo, hi float64, cols, rows int) []string {
+	blocks := []rune("▁▂▃▄▅▆▇█")
+	levels := binChart(points, start, end, cols)
+	lines := make([]string, rows)
+	for r := range rows {
+		// Row r from the top covers eighths base+1 to base+8.
+		base := (rows - 1 - r) * 8
+		var sb strings.Builder
+		for _, v := range levels {
+			if math.IsNaN(v) {
+				sb.WriteRune(' ')
+				continue
+			}
+			level := scaleChart(v, lo, hi, rows*8) + 1
+			switch {
+			case level >= base+8:
+				sb.WriteRune('█')
+			case level > base:
+				sb.WriteRune(blocks[level-base-1])
+			default:
+				sb.WriteRune(' ')
+			}
+		}
+		lines[r] = sb.String()
+	}
+	return lines
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// chartSparkline renders the selected metric as a one-line sparkline of up
+// to width samples, for the compact layout.
+func chartSparkline(history []ObsData, metric chartMetric, width int) string {
+	points, lo, hi := chartPoints(history, metric)
+	if len(points) == 0 || width <= 0 {
+		return ""
+	}
+	return blockChart(points, points[0].t, points[len(points)-1].t, lo, hi, min(width, len(points)), 1)[0]
+}
@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"cmp"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"tempest-cli/tempest"
+	"tempest-cli/units"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// forecastRefresh is how often the dashboard fetches the forecast again.
+// better_forecast is updated about hourly, and responses are cached for
+// ten minutes, so refreshing more often gains nothing.
+const forecastRefresh = 20 * time.Minute
+
+// forecastHours is how far ahead the forecast strip looks.
+const forecastHours = 12
+
+// forecastCardWidth is the width of an hour in the forecast strip, enough
+// for a mini icon and a line of wind; forecastPanelHeight is the height of
+// the strip with its border and header.
+const (
+	forecastCardWidth   = 13
+	forecastPanelHeight = 9
+)
+
+// upcomingHours returns up to forecastHours hours of the forecast, starting
+// with the hour now falls in.
+func upcomingHours(f *tempest.Forecast, now time.Time) []tempest.ForecastHourly {
+	if f == nil {
+		return nil
+	}
+	hours := f.Forecast.Hourly
+	i := sort.Search(len(hours), func(i int) bool { return int64(hours[i].Time)+3600 > now.Unix() })
+	hours = hours[i:]
+	return hours[:min(len(hours), forecastHours)]
+}
+
+// forecastCards returns how many hours fit in a forecast strip width wide.
+func forecastCards(width int) int {
+	return max(1, (width-4)/forecastCardWidth)
+}
+
+// forecastPanelWidth returns the width the current layout gives the
+// forecast strip.
+func forecastPanelWidth(m DashboardModel) int {
+	width := cmp.Or(m.width, 80)
+	if chooseLayout(m.layout, width, m.height) == layoutColumns {
+		return columnsLeftWidth(width)
+	}
+	return width
+}
+
+// scrollForecast moves the forecast strip by n hours, keeping it within the
+// hours that do not fit.
+func (m *DashboardModel) scrollForecast(n int) {
+	hours := upcomingHours(m.station().forecast, time.Now())
+	last := max(0, len(hours)-forecastCards(forecastPanelWidth(*m)))
+	m.forecastScroll = min(last, max(0, m.forecastScroll+n))
+}
+
+// renderForecastPanel renders the next hours of the shown station's
+// forecast as a strip of cards, scrolled by the forecast keys when they do
+// not all fit. It returns "" when there is no forecast to show.
+func renderForecastPanel(m DashboardModel, width int) string {
+	st := m.station()
+	hours := upcomingHours(st.forecast, time.Now())
+	if len(hours) == 0 {
+		return ""
+	}
+	theme := getWeatherTheme(inferWeatherIcon(st.currentObs))
+
+	panelStyle := lipgloss.NewStyle().
+		Width(width - 2).
+		BorderStyle(m.border(panelForecast)).
+		BorderForeground(m.borderColor(panelForecast, theme)).
+		Padding(0, 1)
+	headerStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Text)).Bold(true)
+	labelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Label))
+
+	fit := forecastCards(width)
+	first := min(m.forecastScroll, max(0, len(hours)-fit))
+	shown := hours[first:min(len(hours), first+fit)]
+
+	header := headerStyle.Render(fmt.Sprintf("   NEXT %d HOURS", len(hours)))
+	if len(shown) < len(hours) {
+		header += labelStyle.Render(fmt.Sprintf("   %d-%d of %d  [ ]: scroll", first+1, first+len(shown), len(hours)))
+	}
+
+	cards := make([]string, len(shown))
+	for i, h := range shown {
+		cards[i] = renderHourCard(h, m.units, theme)
+	}
+	return panelStyle.Render(lipgloss.JoinVertical(lipgloss.Left, header, lipgloss.JoinHorizontal(lipgloss.Top, cards...)))
+}
+
+// renderHourCard renders an hour of the forecast: its local time, mini
+// icon, temperature, chance of precipitation and wind.
+func renderHourCard(h tempest.ForecastHourly, prefs units.Prefs, theme WeatherTheme) string {
+	iconStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(getWeatherTheme(h.Icon).Primary))
+	labelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Label))
+	valueStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Text))
+	precipStyle := labelStyle
+	if h.PrecipProbability > 0 {
+		precipStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(getWeatherTheme("rainy").Primary))
+	}
+
+	temp := valueStyle.Render(formatTempShort(units.Temperature(h.AirTemperature), prefs)) +
+		" " + precipStyle.Render(fmt.Sprintf("%d%%", h.PrecipProbability))
+
+	cardStyle := lipgloss.NewStyle().
+		Width(forecastCardWidth).
+		Align(lipgloss.Center)
+
+	return cardStyle.Render(strings.Join([]string{
+		labelStyle.Render(fmt.Sprintf("%02d:00", h.LocalHour)),
+		iconStyle.Render(strings.Join(getWeatherIcon(h.Icon).Mini, "\n")),
+		temp,
+		valueStyle.Render(prefs.FormatSpeed(units.Speed(h.WindAvg)) + " " + h.WindDirectionCardinal),
+	}, "\n"))
+}
+
+// forecastLine summarizes the upcoming hours on one line of at most width
+// columns, for the compact layout.
+func forecastLine(f *tempest.Forecast, prefs units.Prefs, width int) string {
+	var parts []string
+	used := 0
+	for _, h := range upcomingHours(f, time.Now()) {
+		part := fmt.Sprintf("%02dh %s %d%%", h.LocalHour, formatTempShort(units.Temperature(h.AirTemperature), prefs), h.PrecipProbability)
+		if used+lipgloss.Width(part)+2 > width {
+			break
+		}
+		parts = append(parts, part)
+		used += lipgloss.Width(part) + 2
+	}
+	return strings.Join(parts, "  ")
+}
@@ -0,0 +1,238 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"tempest-cli/config"
+	"tempest-cli/units"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// keyAction is something a key does in the dashboard. The actions are
+// named as in config.KeyBindings.
+type keyAction string
+
+const (
+	actQuit        keyAction = "quit"
+	actHelp        keyAction = "help"
+	actNextStation keyAction = "next_station"
+	actPrevStation keyAction = "prev_station"
+	actGrid        keyAction = "grid"
+	actTabs        keyAction = "tabs"
+	actFocus       keyAction = "focus"
+	actForward     keyAction = "forward"
+	actBack        keyAction = "back"
+	actChart       keyAction = "chart"
+	actTempUnit    keyAction = "temp_unit"
+	actWindUnit    keyAction = "wind_unit"
+	actPrecipUnit  keyAction = "precip_unit"
+	actPause       keyAction = "pause"
+	actReconnect   keyAction = "reconnect"
+	actClearEvents keyAction = "clear_events"
+)
+
+// defaultKeys are the keys of each action unless the config file binds
+// others. Keys are named as Bubbletea names them, e.g. "ctrl+c" or "tab".
+var defaultKeys = map[keyAction][]string{
+	actQuit:        {"q", "ctrl+c"},
+	actHelp:        {"?"},
+	actNextStation: {"tab", "right", "l"},
+	actPrevStation: {"shift+tab", "left", "h"},
+	actGrid:        {"g"},
+	actTabs:        {"enter"},
+	actFocus:       {"f"},
+	actForward:     {"]"},
+	actBack:        {"["},
+	actChart:       {"c"},
+	actTempUnit:    {"t"},
+	actWindUnit:    {"w"},
+	actPrecipUnit:  {"r"},
+	actPause:       {"p", " "},
+	actReconnect:   {"R"},
+	actClearEvents: {"x"},
+}
+
+// keyMap maps the keys pressed to the dashboard actions.
+type keyMap struct {
+	actions map[string]keyAction
+	keys    map[keyAction][]string
+}
+
+// quitKey always quits, whatever keys.quit says, so no config can leave the
+// dashboard without a way out.
+const quitKey = "ctrl+c"
+
+// loadKeyMap returns the default keys with the keys.* settings of cfg in
+// place of the defaults they name. A key bound to two actions is an error,
+// as is binding 1-9, which select stations, or quitKey to anything but
+// quit.
+func loadKeyMap(cfg *config.Config) (keyMap, error) {
+	km := keyMap{actions: map[string]keyAction{}, keys: map[keyAction][]string{}}
+	for _, b := range config.KeyBindings {
+		action := keyAction(b.Name)
+		keys := defaultKeys[action]
+		if v, ok := cfg.Get(config.KeyBindingKey(b.Name)); ok {
+			var err error
+			if keys, err = parseKeys(v); err != nil {
+				return keyMap{}, fmt.Errorf("%s: %w", config.KeyBindingKey(b.Name), err)
+			}
+		}
+		if action == actQuit && !slices.Contains(keys, quitKey) {
+			keys = append(slices.Clip(keys), quitKey)
+		}
+		for _, k := range keys {
+			if k == quitKey && action != actQuit {
+				return keyMap{}, fmt.Errorf("%s: %s always quits and cannot be bound", config.KeyBindingKey(b.Name), quitKey)
+			}
+			if len(k) == 1 && k[0] >= '1' && k[0] <= '9' {
+				return keyMap{}, fmt.Errorf("%s: keys 1-9 select stations and cannot be bound", config.KeyBindingKey(b.Name))
+			}
+			if other, ok := km.actions[k]; ok {
+				return keyMap{}, fmt.Errorf("key %q is bound to both %s and %s", keyName(k), other, action)
+			}
+			km.actions[k] = action
+		}
+		km.keys[action] = keys
+	}
+	return km, nil
+}
+
+// parseKeys splits a keys.* setting into key names. "space" stands for the
+// space bar and "comma" for the comma, which separates the keys.
+func parseKeys(s string) ([]string, error) {
+	var keys []string
+	for _, k := range strings.Split(s, ",") {
+		switch k = strings.TrimSpace(k); k {
+		case "":
+			continue
+		case "space":
+			k = " "
+		case "comma":
+			k = ","
+		}
+		keys = append(keys, k)
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no keys given")
+	}
+	return keys, nil
+}
+
+// keyName returns how key is written in the config file and the help.
+func keyName(key string) string {
+	switch key {
+	case " ":
+		return "space"
+	case ",":
+		return "comma"
+	}
+	return key
+}
+
+// action returns the action bound to key.
+func (km keyMap) action(key string) (keyAction, bool) {
+	a, ok := km.actions[key]
+	return a, ok
+}
+
+// first returns the name of the first key bound to action, for hints.
+func (km keyMap) first(action keyAction) string {
+	if keys := km.keys[action]; len(keys) > 0 {
+		return keyName(keys[0])
+	}
+	return ""
+}
+
+// The units the unit keys step through, in order.
+var (
+	tempUnitCycle   = []units.TempUnit{units.Celsius, units.Fahrenheit, units.Kelvin}
+	windUnitCycle   = []units.SpeedUnit{units.MetersPerSecond, units.KilometersPerHour, units.MilesPerHour, units.Knots, units.Beaufort}
+	precipUnitCycle = []units.PrecipUnit{units.Millimeters, units.Centimeters, units.Inches}
+)
+
+// nextUnit returns the unit after cur in cycle, or the first when cur is
+// not in it.
+func nextUnit[T comparable](cycle []T, cur T) T {
+	return cycle[(slices.Index(cycle, cur)+1)%len(cycle)]
+}
+
+// dashPanel is a dashboard panel that can take the focus, which points the
+// scroll keys at it.
+type dashPanel string
+
+const (
+	panelNone     dashPanel = ""
+	panelForecast dashPanel = "forecast"
+	panelChart    dashPanel = "chart"
+	panelEvents   dashPanel = "events"
+)
+
+// nextFocus returns the panel after the focused one, skipping the forecast
+// when there is none, and no panel after the last.
+func (m DashboardModel) nextFocus() dashPanel {
+	panels := []dashPanel{panelNone, panelForecast, panelChart, panelEvents}
+	if len(upcomingHours(m.station().forecast, time.Now())) == 0 {
+		panels = slices.Delete(panels, 1, 2)
+	}
+	return panels[(slices.Index(panels, m.focus)+1)%len(panels)]
+}
+
+// border returns the border of panel: thick when it has the focus, so the
+// focus shows without colors too.
+func (m DashboardModel) border(panel dashPanel) lipgloss.Border {
+	if m.focus == panel && !m.grid {
+		return lipgloss.ThickBorder()
+	}
+	return lipgloss.RoundedBorder()
+}
+
+// borderColor returns the border color of panel: the conditions', or the
+// theme's focus color when the panel has the focus.
+func (m DashboardModel) borderColor(panel dashPanel, theme WeatherTheme) lipgloss.Color {
+	if m.focus == panel && !m.grid {
+		return lipgloss.Color(currentTheme().Focus)
+	}
+	return lipgloss.Color(theme.Secondary)
+}
+
+// renderHelp renders the list of keys, width wide at most.
+func renderHelp(m DashboardModel, width int) string {
+	theme := getWeatherTheme(inferWeatherIcon(m.station().currentObs))
+	headerStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Text)).Bold(true)
+	keyStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Primary))
+	labelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Label))
+	valueStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Text))
+
+	type row struct{ keys, desc string }
+	var rows []row
+	for _, b := range config.KeyBindings {
+		var names []string
+		for _, k := range m.keys.keys[keyAction(b.Name)] {
+			names = append(names, keyName(k))
+		}
+		rows = append(rows, row{strings.Join(names, " "), b.Description})
+	}
+	rows = append(rows, row{"1-9", "Show that station"})
+
+	keysWidth := 0
+	for _, r := range rows {
+		keysWidth = max(keysWidth, lipgloss.Width(r.keys))
+	}
+	lines := []string{headerStyle.Render("KEYS")}
+	for _, r := range rows {
+		lines = append(lines, keyStyle.Render(fmt.Sprintf("%-*s", keysWidth, r.keys))+"  "+valueStyle.Render(r.desc))
+	}
+	lines = append(lines, "", labelStyle.Render("Change them with, e.g., tempest-cli config set "+config.KeyBindingKey(string(actPause))+" p,space"),
+		labelStyle.Render("Press any key to close."))
+
+	return lipgloss.NewStyle().
+		MaxWidth(width).
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color(theme.Secondary)).
+		Padding(0, 2).
+		Render(strings.Join(lines, "\n"))
+}
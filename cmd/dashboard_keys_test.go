@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"tempest-cli/config"
+)
+
+func TestLoadKeyMap(t *testing.T) {
+	tests := []struct {
+		name     string
+		keys     string // the [keys] table
+		quitKeys []string
+		wantErr  string
+	}{
+		{name: "defaults", quitKeys: []string{"q", "ctrl+c"}},
+		{name: "quit rebound", keys: `quit = "esc"`, quitKeys: []string{"esc", "ctrl+c"}},
+		{name: "quit rebound with ctrl+c", keys: `quit = "ctrl+c,esc"`, quitKeys: []string{"ctrl+c", "esc"}},
+		{name: "ctrl+c bound elsewhere", keys: `pause = "ctrl+c"`, wantErr: "ctrl+c always quits"},
+		{name: "ctrl+c moved off quit", keys: "quit = \"esc\"\npause = \"p,ctrl+c\"", wantErr: "ctrl+c always quits"},
+		{name: "key bound twice", keys: `pause = "q"`, wantErr: `key "q" is bound to both quit and pause`},
+		{name: "station key", keys: `help = "1"`, wantErr: "keys 1-9 select stations"},
+		{name: "no keys", keys: `help = " , "`, wantErr: "no keys given"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.toml")
+			if err := os.WriteFile(path, []byte("[keys]\n"+tt.keys+"\n"), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			cfg, err := config.Load(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			km, err := loadKeyMap(cfg)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := km.keys[actQuit]; !slices.Equal(got, tt.quitKeys) {
+				t.Errorf("quit keys = %q, want %q", got, tt.quitKeys)
+			}
+			if a, ok := km.action("ctrl+c"); !ok || a != actQuit {
+				t.Errorf("ctrl+c does %q, want quit", a)
+			}
+		})
+	}
+}
@@ -0,0 +1,260 @@
+package cmd
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"tempest-cli/units"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// dashLayout is an arrangement of the dashboard panels.
+type dashLayout string
+
+const (
+	// layoutColumns puts conditions and wind beside the events.
+	layoutColumns dashLayout = "columns"
+	// layoutStacked stacks every panel vertically.
+	layoutStacked dashLayout = "stacked"
+	// layoutCompact drops the panels for a line per metric.
+	layoutCompact dashLayout = "compact"
+)
+
+// dashLayouts are the values of --layout besides "auto".
+var dashLayouts = []dashLayout{layoutColumns, layoutStacked, layoutCompact}
+
+// The sizes the automatic layout switches at. The stacked layout needs
+// about 30 rows for its panels and at least three events; the columns
+// layout needs room for the conditions panel beside a useful events panel.
+const (
+	columnsMinWidth  = 120
+	columnsMinHeight = 24
+	stackedMinWidth  = 64
+	stackedMinHeight = 30
+)
+
+// windStatsOffset is the width of the wind panel taken by its border,
+// padding and compass, leaving the rest to the sparkline.
+const windStatsOffset = 25
+
+// chooseLayout returns the layout to use for a terminal of width by height.
+// An unknown height, before the first tea.WindowSizeMsg, counts as tall.
+func chooseLayout(want string, width, height int) dashLayout {
+	if want != "" && want != "auto" {
+		return dashLayout(want)
+	}
+	tall := func(rows int) bool { return height <= 0 || height >= rows }
+	switch {
+	case width >= columnsMinWidth && tall(columnsMinHeight):
+		return layoutColumns
+	case width >= stackedMinWidth && tall(stackedMinHeight):
+		return layoutStacked
+	}
+	return layoutCompact
+}
+
+// chartMinHeight is the height of the trends panel with three rows of
+// chart; the layouts leave it out when they cannot give it that much.
+const chartMinHeight = 8
+
+// renderStackedBody stacks the panels at full width. The lightning, rain,
+// forecast, trends and events panels share whatever height is left, in
+// that order.
+func renderStackedBody(m DashboardModel, width, height int) string {
+	conditions := renderConditionsPanel(m, width)
+	wind := renderWindPanel(m, width)
+	forecast := renderForecastPanel(m, width)
+	lightning := hasLightning(m.station(), m.clock())
+	rain := hasRain(m.station(), m.clock())
+	parts := []string{conditions, wind}
+	if height <= 0 {
+		if lightning {
+			parts = append(parts, renderLightningPanel(m, width, lightningPanelHeight))
+		}
+		if rain {
+			parts = append(parts, renderRainPanel(m, width))
+		}
+		if forecast != "" {
+			parts = append(parts, forecast)
+		}
+		parts = append(parts, renderChartPanel(m, width, chartMinHeight+2))
+		return lipgloss.JoinVertical(lipgloss.Left, append(parts, renderEventsPanel(m, width, 0))...)
+	}
+
+	rest := height - lipgloss.Height(conditions) - lipgloss.Height(wind)
+	// The events panel keeps room for at least one event.
+	if lightning && rest-4 >= lightningPanelHeight {
+		parts = append(parts, renderLightningPanel(m, width, lightningPanelHeight))
+		rest -= lightningPanelHeight
+	}
+	if rain && rest-4 >= rainPanelHeight {
+		parts = append(parts, renderRainPanel(m, width))
+		rest -= rainPanelHeight
+	}
+	if forecast != "" && rest-4 >= lipgloss.Height(forecast) {
+		parts = append(parts, forecast)
+		rest -= lipgloss.Height(forecast)
+	}
+	if rest-4 >= chartMinHeight {
+		chart := max(chartMinHeight, rest/2)
+		parts = append(parts, renderChartPanel(m, width, chart))
+		rest -= chart
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, append(parts, renderEventsPanel(m, width, max(4, rest)))...)
+}
+
+// columnsLeftWidth returns the width of the left column of the columns
+// layout on a terminal width wide.
+func columnsLeftWidth(width int) int {
+	return max(72, width*3/5)
+}
+
+// renderColumnsBody puts conditions, wind, the forecast and trends on the
+// left, and on the right the lightning and rain panels, while there is
+// lightning or rain, above the events, which take the rest of the body's
+// height.
+func renderColumnsBody(m DashboardModel, width, height int) string {
+	left := columnsLeftWidth(width)
+	right := width - left
+
+	conditions := renderConditionsPanel(m, left)
+	wind := renderWindPanel(m, left)
+	forecast := renderForecastPanel(m, left)
+	chart := chartMinHeight + 2
+	if height > 0 {
+		chart = height - lipgloss.Height(conditions) - lipgloss.Height(wind)
+	}
+	parts := []string{conditions, wind}
+	if forecast != "" && (height <= 0 || chart >= lipgloss.Height(forecast)) {
+		parts = append(parts, forecast)
+		if height > 0 {
+			chart -= lipgloss.Height(forecast)
+		}
+	}
+	if chart >= chartMinHeight {
+		parts = append(parts, renderChartPanel(m, left, chart))
+	}
+	leftCol := lipgloss.JoinVertical(lipgloss.Left, parts...)
+	if height <= 0 {
+		height = lipgloss.Height(leftCol)
+	}
+	var rightCol []string
+	// The events panel keeps room for at least one event.
+	if hasLightning(m.station(), m.clock()) && height-4 >= lightningPanelHeight {
+		rightCol = append(rightCol, renderLightningPanel(m, right, lightningPanelHeight))
+		height -= lightningPanelHeight
+	}
+	if hasRain(m.station(), m.clock()) && height-4 >= rainPanelHeight {
+		rightCol = append(rightCol, renderRainPanel(m, right))
+		height -= rainPanelHeight
+	}
+	rightCol = append(rightCol, renderEventsPanel(m, right, height))
+	return lipgloss.JoinHorizontal(lipgloss.Top, leftCol, lipgloss.JoinVertical(lipgloss.Left, rightCol...))
+}
+
+// renderCompactDashboard renders the dashboard without panels: a title
+// line, a line per metric, the latest events and the status bar. It is used
+// when the terminal is too small for the panels.
+func renderCompactDashboard(m DashboardModel, width int) string {
+	st := m.station()
+	theme := getWeatherTheme(inferWeatherIcon(st.currentObs))
+	prefs := m.units
+
+	titleStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Text)).Bold(true)
+	labelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Label))
+	valueStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Text))
+	lineStyle := lipgloss.NewStyle().MaxWidth(width)
+
+	indicator, color := connectionIndicator(m)
+	title := st.name
+	if len(m.stations) > 1 {
+		title = fmt.Sprintf("%d/%d %s", m.active+1, len(m.stations), st.name)
+	}
+	indStr := lipgloss.NewStyle().Foreground(lipgloss.Color(color)).Bold(true).Render(indicator)
+	titleStr := titleStyle.Render(title)
+	lines := []string{titleStr + strings.Repeat(" ", max(1, width-lipgloss.Width(titleStr)-lipgloss.Width(indStr))) + indStr}
+
+	metric := func(label, value string) {
+		lines = append(lines, labelStyle.Render(fmt.Sprintf("%-9s", label))+" "+valueStyle.Render(value))
+	}
+	if obs := st.currentObs; obs == nil {
+		waitMsg := "Waiting for observation data..."
+		if m.errMsg != "" {
+			waitMsg = fmt.Sprintf("Error: %s", m.errMsg)
+		}
+		lines = append(lines, labelStyle.Render(waitMsg))
+	} else {
+		sum := obs.Summary
+		if sum == nil {
+			sum = &ObsSummary{}
+		}
+		temp := prefs.FormatTemp(units.Temperature(obs.Temperature))
+		if sum.FeelsLike != nil {
+			temp += ", feels " + prefs.FormatTemp(units.Temperature(*sum.FeelsLike))
+		}
+		metric("Temp", temp+"  "+inferConditionName(obs))
+
+		humidity := fmt.Sprintf("%.0f%%", obs.Humidity)
+		dewPoint := magnusDewPoint(obs.Temperature, obs.Humidity)
+		if sum.DewPoint != nil {
+			dewPoint = *sum.DewPoint
+		}
+		if !math.IsNaN(dewPoint) {
+			humidity += ", dew point " + prefs.FormatTemp(units.Temperature(dewPoint))
+		}
+		metric("Humidity", humidity)
+
+		pressure := prefs.FormatPressure(units.Pressure(obs.Pressure))
+		if arrow, ok := pressureTrendArrows[sum.PressureTrend]; ok {
+			pressure += " " + arrow + " " + sum.PressureTrend
+		}
+		metric("Pressure", pressure)
+
+		windDir, windSpeed := obs.WindDirection, units.Speed(obs.WindAvg)
+		if st.rapidWind != nil {
+			windDir, windSpeed = st.rapidWind.WindDirection, units.Speed(st.rapidWind.WindSpeed)
+		}
+		wind := fmt.Sprintf("%s %s, gust %s", prefs.FormatSpeed(windSpeed), degreesToCardinal(windDir),
+			prefs.FormatSpeed(units.Speed(obs.WindGust)))
+		if spark := renderWindSparkline(st.windHistory, width-lipgloss.Width(wind)-12); spark != "" {
+			wind += "  " + spark
+		}
+		metric("Wind", wind)
+
+		metric("Rain", rainLine(m, st))
+		metric("UV", fmt.Sprintf("%.0f", obs.UV))
+		if hasLightning(st, m.clock()) {
+			metric("Strikes", lightningLine(m, st))
+		} else if sum.StrikeCount1h != nil && sum.StrikeCount3h != nil {
+			metric("Strikes", fmt.Sprintf("%d (1h) %d (3h)", *sum.StrikeCount1h, *sum.StrikeCount3h))
+		}
+
+		chart := chartMetrics[m.chart%len(chartMetrics)]
+		label := fmt.Sprintf(" %s, %s", strings.ToLower(chart.name), shortDuration(m.chartWindowOrDefault()))
+		metric("Trend", chartSparkline(st.obsHistory, chart, width-10-len(label))+label)
+		if next := forecastLine(st.forecast, prefs, width-10); next != "" {
+			metric("Next", next)
+		}
+	}
+
+	status := renderStatusBar(m, width)
+	// Events fill whatever rows are left, at least one.
+	rows := 1
+	if m.height > 0 {
+		rows = max(1, m.height-len(lines)-lipgloss.Height(status))
+	}
+	for _, evt := range st.events[:min(len(st.events), rows)] {
+		style := valueStyle
+		if color, ok := severityColor(evt.Severity); ok {
+			style = lipgloss.NewStyle().Foreground(lipgloss.Color(color))
+		}
+		lines = append(lines, labelStyle.Render(evt.Timestamp.Format("15:04"))+"  "+style.Render(evt.Describe(prefs)))
+	}
+
+	for i, l := range lines {
+		lines[i] = lineStyle.Render(l)
+	}
+	return strings.Join(append(lines, lineStyle.Render(status)), "\n")
+}
@@ -0,0 +1,332 @@
+package cmd
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"tempest-cli/units"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// lightningWindow is the span the lightning panel plots and counts over; the
+// panel is shown while the station has had lightning within it.
+const lightningWindow = 3 * time.Hour
+
+// Storm trends are fitted to the strikes of the last stormTrendWindow, and
+// need at least stormTrendMinStrikes of them spread over stormTrendMinSpan.
+// A storm moving slower than stormSteadyKph counts as stationary.
+const (
+	stormTrendWindow     = 30 * time.Minute
+	stormTrendMinStrikes = 4
+	stormTrendMinSpan    = 10 * time.Minute
+	stormSteadyKph       = 2
+)
+
+// lightningPanelHeight is the height of the lightning panel with its border:
+// four lines of figures, five rows of plot and the time axis.
+const lightningPanelHeight = 13
+
+// lightningMaxKm is the range of the Tempest's lightning sensor, the least
+// the distance axis spans.
+const lightningMaxKm = 40
+
+// lightningCount is the lightning an observation reported: the strikes in
+// its interval and their average distance in km.
+type lightningCount struct {
+	t        time.Time
+	count    int
+	distance float64
+}
+
+// addStrike records a strike event. Strikes are kept for the whole session,
+// in time order.
+func (st *stationState) addStrike(ev EventData) {
+	i := sort.Search(len(st.strikes), func(i int) bool { return st.strikes[i].Timestamp.After(ev.Timestamp) })
+	st.strikes = append(st.strikes[:i], append([]EventData{ev}, st.strikes[i:]...)...)
+}
+
+// addLightningCounts records the observations that saw lightning, keeping
+// one count per timestamp for the whole session, in time order.
+func (st *stationState) addLightningCounts(obs ...ObsData) {
+	for _, o := range obs {
+		if o.LightningCount <= 0 {
+			continue
+		}
+		c := lightningCount{o.Timestamp, o.LightningCount, o.LightningDist}
+		i := sort.Search(len(st.strikeCounts), func(i int) bool { return !st.strikeCounts[i].t.Before(c.t) })
+		if i < len(st.strikeCounts) && st.strikeCounts[i].t.Equal(c.t) {
+			st.strikeCounts[i] = c
+			continue
+		}
+		st.strikeCounts = append(st.strikeCounts[:i], append([]lightningCount{c}, st.strikeCounts[i:]...)...)
+	}
+}
+
+// lightningStats sums up a station's lightning as of a time.
+type lightningStats struct {
+	count1h, count3h int
+	// nearest and last are the nearest and the latest strike in the
+	// lightning window, zero when there was none.
+	nearest, last EventData
+	// points are the strike distances over the window, oldest first.
+	points []chartPoint
+	trend  stormTrend
+}
+
+// lightningSummary returns the station's lightning in the window up to now.
+// Strike events give the distances; where none arrived, the average
+// distances of the observations stand in. The counts are the highest of the
+// strike events, the observations' counts and the server's summary, as each
+// can miss strikes the others saw: events lost while disconnected, or
+// strikes from before the session.
+func lightningSummary(st *stationState, now time.Time) lightningStats {
+	var s lightningStats
+	since := now.Add(-lightningWindow)
+	var events1h, counts1h, counts3h int
+	for _, ev := range st.strikes {
+		if ev.Timestamp.Before(since) || ev.Timestamp.After(now) {
+			continue
+		}
+		s.count3h++
+		if ev.Timestamp.After(now.Add(-time.Hour)) {
+			events1h++
+		}
+		s.points = append(s.points, chartPoint{ev.Timestamp, ev.Distance})
+		if s.nearest.Timestamp.IsZero() || ev.Distance < s.nearest.Distance {
+			s.nearest = ev
+		}
+		s.last = ev
+	}
+	fromEvents := len(s.points) > 0
+	for _, c := range st.strikeCounts {
+		if c.t.Before(since) || c.t.After(now) {
+			continue
+		}
+		counts3h += c.count
+		if c.t.After(now.Add(-time.Hour)) {
+			counts1h += c.count
+		}
+		if fromEvents {
+			continue
+		}
+		s.points = append(s.points, chartPoint{c.t, c.distance})
+		ev := EventData{Timestamp: c.t, Type: EventLightning, Distance: c.distance}
+		if s.nearest.Timestamp.IsZero() || c.distance < s.nearest.Distance {
+			s.nearest = ev
+		}
+		s.last = ev
+	}
+	s.count1h, s.count3h = max(events1h, counts1h), max(s.count3h, counts3h)
+	if obs := st.currentObs; obs != nil && obs.Summary != nil {
+		if n := obs.Summary.StrikeCount1h; n != nil {
+			s.count1h = max(s.count1h, *n)
+		}
+		if n := obs.Summary.StrikeCount3h; n != nil {
+			s.count3h = max(s.count3h, *n)
+		}
+	}
+	s.trend = fitStormTrend(s.points, now)
+	return s
+}
+
+// hasLightning reports whether the station has had lightning in the window
+// up to now.
+func hasLightning(st *stationState, now time.Time) bool {
+	since := now.Add(-lightningWindow)
+	return len(st.strikes) > 0 && st.strikes[len(st.strikes)-1].Timestamp.After(since) ||
+		len(st.strikeCounts) > 0 && st.strikeCounts[len(st.strikeCounts)-1].t.After(since)
+}
+
+// stormTrend is the movement of a storm fitted to its strike distances.
+type stormTrend struct {
+	// ok is false when there are too few recent strikes for a fit.
+	ok bool
+	// speed is how fast the storm closes in, in km/h; negative when it
+	// moves away. distance is the fitted distance now, in km.
+	speed, distance float64
+}
+
+// fitStormTrend fits a straight line through the distances of the strikes
+// of the last stormTrendWindow by least squares.
+func fitStormTrend(points []chartPoint, now time.Time) stormTrend {
+	i := sort.Search(len(points), func(i int) bool { return points[i].t.After(now.Add(-stormTrendWindow)) })
+	points = points[i:]
+	if len(points) < stormTrendMinStrikes || points[len(points)-1].t.Sub(points[0].t) < stormTrendMinSpan {
+		return stormTrend{}
+	}
+
+	// Hours before now against km.
+	var sx, sy, sxx, sxy float64
+	for _, p := range points {
+		x := now.Sub(p.t).Hours()
+		sx, sy, sxx, sxy = sx+x, sy+p.v, sxx+x*x, sxy+x*p.v
+	}
+	n := float64(len(points))
+	slope := (n*sxy - sx*sy) / (n*sxx - sx*sx)
+	return stormTrend{
+		ok:       true,
+		speed:    slope,
+		distance: math.Max(0, (sy-slope*sx)/n),
+	}
+}
+
+// describe reports the trend in the units of prefs as a headline and a
+// detail line, with the severity to show them with. The detail of an
+// approaching storm tells when it comes within nearbyStrikeKm.
+func (t stormTrend) describe(prefs units.Prefs, now time.Time) (headline, detail string, severity EventSeverity) {
+	speed := prefs.FormatDistance(units.Distance(math.Abs(t.speed))) + "/h"
+	away := "about " + prefs.FormatDistance(units.Distance(t.distance)) + " away"
+	switch {
+	case !t.ok:
+		return "Too few recent strikes for a trend", "", SeverityInfo
+	case t.distance <= nearbyStrikeKm:
+		return "Storm overhead", "Strikes within " + prefs.FormatDistance(nearbyStrikeKm), SeverityAlert
+	case t.speed <= -stormSteadyKph:
+		return "Receding at " + speed, away, SeverityInfo
+	case t.speed < stormSteadyKph:
+		return "Stationary", away, SeverityWarning
+	}
+	eta := time.Duration((t.distance - nearbyStrikeKm) / t.speed * float64(time.Hour)).Round(time.Minute)
+	return "Approaching at " + speed, fmt.Sprintf("%s, within %s in ~%s (%s)",
+		prefs.FormatDistance(units.Distance(t.distance)), prefs.FormatDistance(nearbyStrikeKm),
+		shortDuration(eta), now.Add(eta).Format("15:04")), SeverityAlert
+}
+
+// renderLightningPanel renders the lightning tracker, height rows tall with
+// its border: the strike counts, the nearest and latest strike, the storm's
+// movement and a plot of strike distance over the lightning window.
+func renderLightningPanel(m DashboardModel, width, height int) string {
+	st := m.station()
+	theme := getWeatherTheme(inferWeatherIcon(st.currentObs))
+	now := m.clock()
+	stats := lightningSummary(st, now)
+
+	panelStyle := lipgloss.NewStyle().
+		Width(width - 2).
+		Height(height - 2).
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color(theme.Secondary)).
+		Padding(0, 2)
+	headerStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Text)).Bold(true)
+	labelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Label))
+	valueStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Text))
+	dotStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(getWeatherTheme("thunderstorm").Primary))
+
+	header := headerStyle.Render("  LIGHTNING") +
+		"   " + labelStyle.Render("1h ") + valueStyle.Render(fmt.Sprint(stats.count1h)) +
+		"  " + labelStyle.Render("3h ") + valueStyle.Render(fmt.Sprint(stats.count3h))
+	strikes := labelStyle.Render("  No strikes in the last " + shortDuration(lightningWindow))
+	if !stats.last.Timestamp.IsZero() {
+		strikes = "  " + labelStyle.Render("nearest ") +
+			valueStyle.Render(m.units.FormatDistance(units.Distance(stats.nearest.Distance))+" "+stats.nearest.Timestamp.Format("15:04")) +
+			"  " + labelStyle.Render("last ") +
+			valueStyle.Render(stats.last.Timestamp.Format("15:04")+", "+shortDuration(max(0, now.Sub(stats.last.Timestamp)))+" ago")
+	}
+	headline, detail, severity := stats.trend.describe(m.units, now)
+	trendStyle := valueStyle
+	if color, ok := severityColor(severity); ok {
+		trendStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(color)).Bold(true)
+	}
+
+	// Distance rises up the y axis from the station at 0 to the sensor's
+	// range, or the farthest strike if beyond it.
+	top := float64(lightningMaxKm)
+	for _, p := range stats.points {
+		top = math.Max(top, p.v)
+	}
+	hiLabel, loLabel := m.units.FormatDistance(units.Distance(top)), m.units.FormatDistance(0)
+	axisWidth := max(lipgloss.Width(hiLabel), lipgloss.Width(loLabel))
+	rows := max(1, height-2-4-2) // border, figures, x axis and its labels
+	cols := max(1, width-2-4-axisWidth-2)
+	start := now.Add(-lightningWindow)
+
+	var plot []string
+	if m.chartStyle == "block" {
+		plot = blockScatter(stats.points, start, now, top, cols, rows)
+	} else {
+		plot = brailleScatter(stats.points, start, now, top, cols, rows)
+	}
+
+	lines := []string{header, strikes, "  " + trendStyle.Render(headline), "  " + valueStyle.Render(detail)}
+	for i, row := range plot {
+		label, tick := "", "│"
+		switch i {
+		case 0:
+			label, tick = hiLabel, "┤"
+		case len(plot) - 1:
+			label, tick = loLabel, "┤"
+		}
+		lines = append(lines, labelStyle.Render(fmt.Sprintf("%*s %s", axisWidth, label, tick))+dotStyle.Render(row))
+	}
+	lines = append(lines,
+		labelStyle.Render(strings.Repeat(" ", axisWidth+1)+"└"+strings.Repeat("─", cols)),
+		labelStyle.Render(strings.Repeat(" ", axisWidth+2)+timeAxisLabels(start, now, cols)),
+	)
+	return panelStyle.Render(strings.Join(lines, "\n"))
+}
+
+// scatterCell returns where a point falls in a w by h grid spanning start
+// to end and 0 to top, counting y up from the bottom, and whether it falls
+// in the grid at all.
+func scatterCell(p chartPoint, start, end time.Time, top float64, w, h int) (x, y int, ok bool) {
+	span := end.Sub(start)
+	if p.t.Before(start) || p.t.After(end) || span <= 0 {
+		return 0, 0, false
+	}
+	x = min(w-1, int(float64(p.t.Sub(start))/float64(span)*float64(w)))
+	return x, scaleChart(p.v, 0, top, h), true
+}
+
+// brailleScatter draws each point as a braille dot in a cols by rows grid.
+func brailleScatter(points []chartPoint, start, end time.Time, top float64, cols, rows int) []string {
+	w, h := cols*2, rows*4
+	dots := make([][]bool, h)
+	for y := range dots {
+		dots[y] = make([]bool, w)
+	}
+	for _, p := range points {
+		if x, y, ok := scatterCell(p, start, end, top, w, h); ok {
+			dots[h-1-y][x] = true
+		}
+	}
+	return brailleRows(dots, cols, rows)
+}
+
+// blockScatter draws each point as a bullet in a cols by rows grid, for
+// terminals whose font lacks braille.
+func blockScatter(points []chartPoint, start, end time.Time, top float64, cols, rows int) []string {
+	grid := make([][]rune, rows)
+	for r := range grid {
+		grid[r] = []rune(strings.Repeat(" ", cols))
+	}
+	for _, p := range points {
+		if x, y, ok := scatterCell(p, start, end, top, cols, rows); ok {
+			grid[rows-1-y][x] = '•'
+		}
+	}
+	lines := make([]string, rows)
+	for r, row := range grid {
+		lines[r] = string(row)
+	}
+	return lines
+}
+
+// lightningLine sums up the station's lightning on one line, for the
+// compact layout.
+func lightningLine(m DashboardModel, st *stationState) string {
+	now := m.clock()
+	stats := lightningSummary(st, now)
+	line := fmt.Sprintf("%d (1h) %d (3h)", stats.count1h, stats.count3h)
+	if !stats.nearest.Timestamp.IsZero() {
+		line += ", nearest " + m.units.FormatDistance(units.Distance(stats.nearest.Distance))
+	}
+	if stats.trend.ok {
+		headline, detail, _ := stats.trend.describe(m.units, now)
+		line += ", " + strings.ToLower(headline) + ", " + detail
+	}
+	return line
+}
@@ -0,0 +1,244 @@
+package cmd
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"tempest-cli/units"
+)
+
+// stormPoints returns a strike every interval over the span up to now, at
+// the distance dist gives for the hours before now.
+func stormPoints(now time.Time, span, interval time.Duration, dist func(hours float64) float64) []chartPoint {
+	var points []chartPoint
+	for d := span; d >= 0; d -= interval {
+		points = append(points, chartPoint{now.Add(-d), dist(d.Hours())})
+	}
+	return points
+}
+
+func TestFitStormTrend(t *testing.T) {
+	now := time.Date(2025, 7, 4, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name         string
+		points       []chartPoint
+		wantOK       bool
+		wantSpeed    float64
+		wantDistance float64
+	}{
+		{
+			name:   "approaching",
+			points: stormPoints(now, 25*time.Minute, 5*time.Minute, func(h float64) float64 { return 20 + 30*h }),
+			wantOK: true, wantSpeed: 30, wantDistance: 20,
+		},
+		{
+			name:   "receding",
+			points: stormPoints(now, 25*time.Minute, 5*time.Minute, func(h float64) float64 { return 20 - 12*h }),
+			wantOK: true, wantSpeed: -12, wantDistance: 20,
+		},
+		{
+			name:   "stationary",
+			points: stormPoints(now, 25*time.Minute, 5*time.Minute, func(float64) float64 { return 25 }),
+			wantOK: true, wantSpeed: 0, wantDistance: 25,
+		},
+		{
+			// Closing in at 60 km/h, the line passes the station before now;
+			// the distance stops at 0.
+			name: "arrived",
+			points: stormPoints(now.Add(-5*time.Minute), 20*time.Minute, 5*time.Minute, func(h float64) float64 {
+				return 60 * h
+			}),
+			wantOK: true, wantSpeed: 60, wantDistance: 0,
+		},
+		{
+			name:   "too few strikes",
+			points: stormPoints(now, 20*time.Minute, 10*time.Minute, func(float64) float64 { return 25 }),
+		},
+		{
+			name:   "span too short",
+			points: stormPoints(now, stormTrendMinSpan-time.Minute, 3*time.Minute, func(float64) float64 { return 25 }),
+		},
+		{
+			name: "older strikes left out",
+			points: append(
+				[]chartPoint{{now.Add(-stormTrendWindow - time.Minute), 40}},
+				stormPoints(now, 20*time.Minute, 10*time.Minute, func(float64) float64 { return 25 })...,
+			),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := fitStormTrend(tt.points, now)
+			if got.ok != tt.wantOK {
+				t.Fatalf("ok = %v, want %v", got.ok, tt.wantOK)
+			}
+			if math.Abs(got.speed-tt.wantSpeed) > 1e-6 || math.Abs(got.distance-tt.wantDistance) > 1e-6 {
+				t.Errorf("got %.3f km/h at %.3f km, want %.3f km/h at %.3f km", got.speed, got.distance, tt.wantSpeed, tt.wantDistance)
+			}
+		})
+	}
+}
+
+func TestStormTrendDescribe(t *testing.T) {
+	now := time.Date(2025, 7, 4, 12, 0, 0, 0, time.UTC)
+	imperial := units.Prefs{Distance: units.Miles}
+	tests := []struct {
+		name         string
+		trend        stormTrend
+		prefs        units.Prefs
+		wantHeadline string
+		wantDetail   string
+		wantSeverity EventSeverity
+	}{
+		{
+			name:         "no trend",
+			trend:        stormTrend{},
+			prefs:        units.Metric(),
+			wantHeadline: "Too few recent strikes for a trend", wantSeverity: SeverityInfo,
+		},
+		{
+			// 10 km to go at 30 km/h takes 20 minutes.
+			name:         "approaching",
+			trend:        stormTrend{ok: true, speed: 30, distance: 20},
+			prefs:        units.Metric(),
+			wantHeadline: "Approaching at 30 km/h",
+			wantDetail:   "20 km, within 10 km in ~20m (12:20)",
+			wantSeverity: SeverityAlert,
+		},
+		{
+			// 40 km to go at 16 km/h takes 2h30m.
+			name:         "approaching slowly",
+			trend:        stormTrend{ok: true, speed: 16, distance: 50},
+			prefs:        units.Metric(),
+			wantHeadline: "Approaching at 16 km/h",
+			wantDetail:   "50 km, within 10 km in ~2h30m (14:30)",
+			wantSeverity: SeverityAlert,
+		},
+		{
+			name:         "approaching in miles",
+			trend:        stormTrend{ok: true, speed: 30, distance: 20},
+			prefs:        imperial,
+			wantHeadline: "Approaching at 19 mi/h",
+			wantDetail:   "12 mi, within 6 mi in ~20m (12:20)",
+			wantSeverity: SeverityAlert,
+		},
+		{
+			name:         "receding",
+			trend:        stormTrend{ok: true, speed: -12, distance: 20},
+			prefs:        units.Metric(),
+			wantHeadline: "Receding at 12 km/h", wantDetail: "about 20 km away",
+			wantSeverity: SeverityInfo,
+		},
+		{
+			name:         "stationary",
+			trend:        stormTrend{ok: true, speed: stormSteadyKph - 0.5, distance: 25},
+			prefs:        units.Metric(),
+			wantHeadline: "Stationary", wantDetail: "about 25 km away",
+			wantSeverity: SeverityWarning,
+		},
+		{
+			name:         "barely receding is stationary",
+			trend:        stormTrend{ok: true, speed: -stormSteadyKph + 0.5, distance: 25},
+			prefs:        units.Metric(),
+			wantHeadline: "Stationary", wantDetail: "about 25 km away",
+			wantSeverity: SeverityWarning,
+		},
+		{
+			name:         "overhead",
+			trend:        stormTrend{ok: true, speed: 30, distance: nearbyStrikeKm},
+			prefs:        units.Metric(),
+			wantHeadline: "Storm overhead", wantDetail: "Strikes within 10 km",
+			wantSeverity: SeverityAlert,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			headline, detail, severity := tt.trend.describe(tt.prefs, now)
+			if headline != tt.wantHeadline || detail != tt.wantDetail || severity != tt.wantSeverity {
+				t.Errorf("got %q, %q, %s\nwant %q, %q, %s",
+					headline, detail, severity, tt.wantHeadline, tt.wantDetail, tt.wantSeverity)
+			}
+		})
+	}
+}
+
+func TestLightningSummary(t *testing.T) {
+	now := time.Date(2025, 7, 4, 12, 0, 0, 0, time.UTC)
+	strike := func(ago time.Duration, km float64) EventData {
+		return EventData{Timestamp: now.Add(-ago), Type: EventLightning, Distance: km}
+	}
+	obs := func(ago time.Duration, count int, km float64) ObsData {
+		return ObsData{Timestamp: now.Add(-ago), LightningCount: count, LightningDist: km}
+	}
+	count := func(n int) *int { return &n }
+
+	t.Run("strike events", func(t *testing.T) {
+		st := &stationState{}
+		st.addStrike(strike(30*time.Minute, 18))
+		st.addStrike(strike(2*time.Hour, 12))
+		st.addStrike(strike(5*time.Minute, 15))
+		st.addStrike(strike(4*time.Hour, 3)) // before the window
+		st.addLightningCounts(obs(30*time.Minute, 1, 30))
+
+		s := lightningSummary(st, now)
+		if s.count1h != 2 || s.count3h != 3 {
+			t.Errorf("counts %d (1h) %d (3h), want 2 and 3", s.count1h, s.count3h)
+		}
+		if s.nearest.Distance != 12 || !s.nearest.Timestamp.Equal(now.Add(-2*time.Hour)) {
+			t.Errorf("nearest %v km at %v, want 12 km two hours ago", s.nearest.Distance, s.nearest.Timestamp)
+		}
+		if !s.last.Timestamp.Equal(now.Add(-5 * time.Minute)) {
+			t.Errorf("last at %v, want five minutes ago", s.last.Timestamp)
+		}
+		// The observation's average distance is not plotted next to the
+		// events.
+		if len(s.points) != 3 {
+			t.Errorf("plotted %d points, want the 3 strike events", len(s.points))
+		}
+	})
+
+	t.Run("observation counts stand in for events", func(t *testing.T) {
+		st := &stationState{}
+		st.addLightningCounts(
+			obs(90*time.Minute, 4, 22),
+			obs(20*time.Minute, 2, 14),
+			obs(10*time.Minute, 0, 0), // no lightning
+		)
+
+		s := lightningSummary(st, now)
+		if s.count1h != 2 || s.count3h != 6 {
+			t.Errorf("counts %d (1h) %d (3h), want 2 and 6", s.count1h, s.count3h)
+		}
+		if len(s.points) != 2 {
+			t.Errorf("plotted %d points, want 2", len(s.points))
+		}
+		if s.nearest.Distance != 14 || !s.last.Timestamp.Equal(now.Add(-20*time.Minute)) {
+			t.Errorf("nearest %v km, last at %v; want 14 km, twenty minutes ago", s.nearest.Distance, s.last.Timestamp)
+		}
+	})
+
+	t.Run("highest count wins", func(t *testing.T) {
+		st := &stationState{}
+		st.addStrike(strike(10*time.Minute, 15))
+		// More strikes were counted than arrived as events.
+		st.addLightningCounts(obs(10*time.Minute, 3, 15), obs(2*time.Hour, 2, 20))
+		st.currentObs = &ObsData{Summary: &ObsSummary{StrikeCount1h: count(1), StrikeCount3h: count(9)}}
+
+		s := lightningSummary(st, now)
+		if s.count1h != 3 || s.count3h != 9 {
+			t.Errorf("counts %d (1h) %d (3h), want 3 from the observations and 9 from the summary", s.count1h, s.count3h)
+		}
+	})
+
+	t.Run("approaching storm", func(t *testing.T) {
+		st := &stationState{}
+		for _, p := range stormPoints(now, 25*time.Minute, 5*time.Minute, func(h float64) float64 { return 20 + 30*h }) {
+			st.addStrike(EventData{Timestamp: p.t, Type: EventLightning, Distance: p.v})
+		}
+		s := lightningSummary(st, now)
+		if !s.trend.ok || math.Abs(s.trend.speed-30) > 1e-6 || math.Abs(s.trend.distance-20) > 1e-6 {
+			t.Errorf("trend %+v, want 30 km/h at 20 km", s.trend)
+		}
+	})
+}
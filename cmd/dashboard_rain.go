@@ -0,0 +1,221 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"tempest-cli/units"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// rainEventGap is how long it must stay dry for a rain event to end.
+const rainEventGap = 30 * time.Minute
+
+// rainPanelHeight is the height of the rain panel with its border.
+const rainPanelHeight = 6
+
+// rainSample is the rain an observation collected: mm over its interval.
+type rainSample struct {
+	t        time.Time
+	accum    float64
+	interval time.Duration
+}
+
+// addRainObs records the observations that collected rain, keeping one
+// sample per timestamp for the whole session, in time order.
+func (st *stationState) addRainObs(obs ...ObsData) {
+	for _, o := range obs {
+		if o.PrecipAccum <= 0 {
+			continue
+		}
+		r := rainSample{o.Timestamp, o.PrecipAccum, reportInterval(o)}
+		i := sort.Search(len(st.rain), func(i int) bool { return !st.rain[i].t.Before(r.t) })
+		if i < len(st.rain) && st.rain[i].t.Equal(r.t) {
+			st.rain[i] = r
+			continue
+		}
+		st.rain = append(st.rain[:i], append([]rainSample{r}, st.rain[i:]...)...)
+	}
+}
+
+// reportInterval returns the span an observation covers; the Tempest
+// reports every minute unless it says otherwise.
+func reportInterval(o ObsData) time.Duration {
+	return time.Duration(max(1, o.ReportInterval)) * time.Minute
+}
+
+// rainRate returns the rate the observation's rain fell at, in mm/h.
+func rainRate(o ObsData) float64 {
+	return o.PrecipAccum / reportInterval(o).Hours()
+}
+
+// rainIntensity classes a rain rate in mm/h, by the American Meteorological
+// Society's bounds.
+func rainIntensity(mmh float64) string {
+	switch {
+	case mmh <= 0:
+		return "none"
+	case mmh <= 2.5:
+		return "light"
+	case mmh <= 7.6:
+		return "moderate"
+	case mmh <= 50:
+		return "heavy"
+	}
+	return "violent"
+}
+
+// rainStats sums up a station's rain as of a time. Amounts are in mm.
+type rainStats struct {
+	// rate is the rate of the latest observation, in mm/h, and kind its
+	// precipitation type.
+	rate float64
+	kind string
+	// start is when the current rain event began, zero when it is dry;
+	// event is what the event has brought so far.
+	start time.Time
+	event float64
+	// lastHour and today are nil when unknown.
+	lastHour, today *float64
+}
+
+// rainSummary returns the station's rain as of now. A rain event starts
+// with the rain event the station sends, or else the first observation to
+// collect rain, and lasts until rainEventGap passes without rain. The last
+// hour's total is the server's when it sends one, as it covers the time
+// before the session too.
+func rainSummary(st *stationState, now time.Time) rainStats {
+	var s rainStats
+	if obs := st.currentObs; obs != nil {
+		s.rate, s.kind = rainRate(*obs), obs.PrecipTypeName()
+		s.today = &obs.DailyRain
+		if obs.Summary != nil && obs.Summary.PrecipTotal1h != nil {
+			s.lastHour = obs.Summary.PrecipTotal1h
+		}
+	}
+
+	var hour float64
+	for _, r := range st.rain {
+		if r.t.After(now.Add(-time.Hour)) && !r.t.After(now) {
+			hour += r.accum
+		}
+	}
+	if s.lastHour == nil && st.currentObs != nil {
+		s.lastHour = &hour
+	}
+
+	// The current event is the run of wet observations that ends within
+	// rainEventGap of now, each within rainEventGap of the one before.
+	end := sort.Search(len(st.rain), func(i int) bool { return st.rain[i].t.After(now) })
+	if end > 0 && now.Sub(st.rain[end-1].t) <= rainEventGap {
+		first := end - 1
+		for first > 0 && st.rain[first].t.Sub(st.rain[first-1].t) <= rainEventGap {
+			first--
+		}
+		for _, r := range st.rain[first:end] {
+			s.event += r.accum
+		}
+		s.start = st.rain[first].t.Add(-st.rain[first].interval)
+	}
+	// The station's rain event marks the start more precisely, and comes
+	// before the first observation to collect any rain.
+	if started := st.rainStarted; !started.IsZero() && !started.After(now) {
+		switch {
+		case s.start.IsZero() && now.Sub(started) <= rainEventGap:
+			s.start = started
+		case !s.start.IsZero() && started.Before(s.start) && s.start.Sub(started) <= rainEventGap:
+			s.start = started
+		}
+	}
+	return s
+}
+
+// hasRain reports whether the station has had rain today, or a rain event
+// is under way.
+func hasRain(st *stationState, now time.Time) bool {
+	s := rainSummary(st, now)
+	return !s.start.IsZero() || s.today != nil && *s.today > 0
+}
+
+// formatRainRate formats a rate in mm/h in the precipitation unit per hour.
+func formatRainRate(prefs units.Prefs, mmh float64) string {
+	return prefs.FormatPrecip(units.Precip(mmh)) + "/h"
+}
+
+// rainHeadline describes the rain falling now, e.g. "Moderate rain".
+func rainHeadline(s rainStats) string {
+	intensity := rainIntensity(s.rate)
+	if intensity == "none" {
+		return "Not raining"
+	}
+	kind := s.kind
+	if kind == "none" {
+		kind = "rain"
+	}
+	return strings.ToUpper(intensity[:1]) + intensity[1:] + " " + kind
+}
+
+// renderRainPanel renders the rain now, the current rain event and the
+// totals of the last hour and the day.
+func renderRainPanel(m DashboardModel, width int) string {
+	st := m.station()
+	theme := getWeatherTheme(inferWeatherIcon(st.currentObs))
+	now := m.clock()
+	s := rainSummary(st, now)
+	prefs := m.units
+
+	panelStyle := lipgloss.NewStyle().
+		Width(width - 2).
+		Height(rainPanelHeight - 2).
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color(theme.Secondary)).
+		Padding(0, 2)
+	headerStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Text)).Bold(true)
+	labelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Label))
+	valueStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Text))
+	rainStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(getWeatherTheme("rainy").Primary)).Bold(true)
+
+	total := func(mm *float64) string {
+		if mm == nil {
+			return "--"
+		}
+		return prefs.FormatPrecip(units.Precip(*mm))
+	}
+
+	header := headerStyle.Render("  RAIN") + "   " + rainStyle.Render(rainHeadline(s))
+	rate := "  " + labelStyle.Render("Rate ") + valueStyle.Render(formatRainRate(prefs, s.rate)) +
+		"  " + labelStyle.Render("Type ") + valueStyle.Render(s.kind)
+	event := "  " + labelStyle.Render("No rain in the last "+shortDuration(rainEventGap))
+	if !s.start.IsZero() {
+		event = "  " + labelStyle.Render("Since ") +
+			valueStyle.Render(s.start.Format("15:04")+" ("+shortDuration(max(0, now.Sub(s.start)))+")") +
+			"  " + labelStyle.Render("Event ") + valueStyle.Render(prefs.FormatPrecip(units.Precip(s.event)))
+	}
+	totals := "  " + labelStyle.Render("Last hour ") + valueStyle.Render(total(s.lastHour)) +
+		"  " + labelStyle.Render("Today ") + valueStyle.Render(total(s.today))
+
+	return panelStyle.Render(strings.Join([]string{header, rate, event, totals}, "\n"))
+}
+
+// rainLine sums up the station's rain on one line, for the compact layout.
+func rainLine(m DashboardModel, st *stationState) string {
+	now := m.clock()
+	s := rainSummary(st, now)
+	var parts []string
+	if s.rate > 0 {
+		parts = append(parts, formatRainRate(m.units, s.rate)+" "+strings.ToLower(rainHeadline(s)))
+	}
+	if !s.start.IsZero() {
+		parts = append(parts, fmt.Sprintf("%s since %s", m.units.FormatPrecip(units.Precip(s.event)), s.start.Format("15:04")))
+	}
+	if s.lastHour != nil {
+		parts = append(parts, m.units.FormatPrecip(units.Precip(*s.lastHour))+" last hour")
+	}
+	if s.today != nil {
+		parts = append(parts, m.units.FormatPrecip(units.Precip(*s.today))+" today")
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestReceiveWhilePaused(t *testing.T) {
+	a, b := deviceRef{serial: "ST-A"}, deviceRef{serial: "ST-B"}
+	at := func(s int) time.Time { return time.Unix(1588948614+int64(s), 0) }
+	wind := func(from deviceRef, s int) tea.Msg {
+		return wsRapidWindMsg{from: from, wind: RapidWindData{Timestamp: at(s)}}
+	}
+	obs := func(from deviceRef, s int) tea.Msg { return wsObsMsg{from: from, obs: ObsData{Timestamp: at(s)}} }
+	strike := func(s int) tea.Msg { return wsEventMsg{event: EventData{Timestamp: at(s), Type: EventLightning}} }
+
+	m := DashboardModel{paused: true}
+	for s := 0; s < 3000; s += 3 {
+		m.receive(wind(a, s))
+		m.receive(wind(b, s))
+		if s%60 == 0 {
+			m.receive(obs(a, s))
+		}
+	}
+	m.receive(strike(100))
+	m.receive(strike(200))
+	m.receive(wind(a, 3000))
+
+	want := []tea.Msg{obs(a, 2940), wind(b, 2997), strike(100), strike(200), wind(a, 3000)}
+	if !reflect.DeepEqual(m.held, want) {
+		t.Errorf("held %d messages:\n%#v\nwant\n%#v", len(m.held), m.held, want)
+	}
+}
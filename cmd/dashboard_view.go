@@ -1,58 +1,18 @@
 package cmd
 
 import (
+	"cmp"
 	"fmt"
 	"math"
+	"sort"
 	"strings"
 	"time"
 
+	"tempest-cli/units"
+
 	"github.com/charmbracelet/lipgloss"
 )
 
-// --- Unit conversion (WS sends raw metric) ---
-
-func convertTemp(c float64, prefs UnitPrefs) float64 {
-	if prefs.TempF {
-		return c*9.0/5.0 + 32.0
-	}
-	return c
-}
-
-func convertWind(ms float64, prefs UnitPrefs) float64 {
-	if prefs.WindMph {
-		return ms * 2.23694
-	}
-	return ms
-}
-
-func convertPrecip(mm float64, prefs UnitPrefs) float64 {
-	if prefs.PrecipIn {
-		return mm / 25.4
-	}
-	return mm
-}
-
-func windUnitLabel(prefs UnitPrefs) string {
-	if prefs.WindMph {
-		return "mph"
-	}
-	return "m/s"
-}
-
-func tempUnitLabel(prefs UnitPrefs) string {
-	if prefs.TempF {
-		return "F"
-	}
-	return "C"
-}
-
-func precipUnitLabel(prefs UnitPrefs) string {
-	if prefs.PrecipIn {
-		return "in"
-	}
-	return "mm"
-}
-
 // --- Icon inference (WS obs_st has no conditions string) ---
 
 func inferWeatherIcon(obs *ObsData) string {
@@ -131,7 +91,12 @@ func renderWindCompass(direction int) string {
 
 // --- Wind sparkline ---
 
-func renderWindSparkline(history []RapidWindData) string {
+// renderWindSparkline charts the last n samples of history, one column per
+// sample.
+func renderWindSparkline(history []RapidWindData, n int) string {
+	if len(history) > n {
+		history = history[len(history)-max(n, 0):]
+	}
 	if len(history) == 0 {
 		return ""
 	}
@@ -169,44 +134,92 @@ func renderWindSparkline(history []RapidWindData) string {
 
 func renderDashboard(m DashboardModel) string {
 	width := m.width
-	if width <= 0 || width > 80 {
+	if width <= 0 {
 		width = 80
 	}
 
-	header := renderDashHeader(m, width)
-	conditions := renderConditionsPanel(m, width)
-	wind := renderWindPanel(m, width)
-	events := renderEventsPanel(m, width)
-	status := renderStatusBar(m, width)
+	layout := chooseLayout(m.layout, width, m.height)
+	if m.help {
+		return lipgloss.Place(width, max(m.height, 1), lipgloss.Center, lipgloss.Center, renderHelp(m, width))
+	}
+	if layout == layoutCompact && !m.grid {
+		return renderCompactDashboard(m, width)
+	}
 
-	return lipgloss.JoinVertical(lipgloss.Left,
-		header,
-		conditions,
-		wind,
-		events,
-		status,
-	)
+	top := []string{renderDashHeader(m, width)}
+	if len(m.stations) > 1 && !m.grid {
+		top = append(top, renderStationTabs(m, width))
+	}
+	status := renderStatusBar(m, width)
+	// bodyHeight is what is left for the panels, 0 when the terminal size
+	// is not known yet.
+	bodyHeight := 0
+	if m.height > 0 {
+		bodyHeight = max(0, m.height-lipgloss.Height(strings.Join(top, "\n"))-lipgloss.Height(status))
+	}
+
+	var body string
+	switch {
+	case m.grid:
+		body = renderStationGrid(m, width)
+	case layout == layoutColumns:
+		body = renderColumnsBody(m, width, bodyHeight)
+	default:
+		body = renderStackedBody(m, width, bodyHeight)
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, append(top, body, status)...)
 }
 
-func renderDashHeader(m DashboardModel, width int) string {
-	theme := getWeatherTheme(inferWeatherIcon(m.currentObs))
-
-	indicator := "[CONNECTING...]"
-	indicatorColor := "#FFAA00"
+// connectionIndicator returns the connection state label of the header and
+// its color.
+func connectionIndicator(m DashboardModel) (indicator, color string) {
+	t := currentTheme()
+	indicator = "[CONNECTING...]"
+	color = t.Warning
 	if m.connected {
 		indicator = "[LIVE]"
-		indicatorColor = "#00FF00"
+		if m.source == "udp" {
+			indicator = "[LIVE UDP]"
+		}
+		color = t.OK
+	}
+	if m.source == "replay" {
+		indicator = fmt.Sprintf("[REPLAY %gx]", m.replaySpeed)
+		if m.replaySpeed == 0 {
+			indicator = "[REPLAY]"
+		}
+		color = t.Info
+		if m.replayDone {
+			indicator = "[REPLAY ENDED]"
+		}
+	}
+	if m.reconnecting {
+		indicator = "[RECONNECTING...]"
 	}
 	if m.errMsg != "" && !m.reconnecting {
 		indicator = "[DISCONNECTED]"
-		indicatorColor = "#FF0000"
+		color = t.Alert
+	}
+	if m.paused {
+		indicator = "[PAUSED]"
+		color = t.Warning
 	}
+	return indicator, color
+}
+
+func renderDashHeader(m DashboardModel, width int) string {
+	st := m.station()
+	theme := getWeatherTheme(inferWeatherIcon(st.currentObs))
+	indicator, indicatorColor := connectionIndicator(m)
 
 	indicatorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(indicatorColor)).Bold(true)
-	titleStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#FFFFFF")).Bold(true)
+	titleStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Text)).Bold(true)
 
 	innerWidth := width - 4 // account for border + padding
-	title := fmt.Sprintf("%s (%s)", m.stationName, m.timezone)
+	title := fmt.Sprintf("%s (%s)", st.name, cmp.Or(st.source, st.timezone))
+	if m.grid {
+		title = fmt.Sprintf("%d stations", len(m.stations))
+	}
 	indStr := indicatorStyle.Render(indicator)
 	titleStr := titleStyle.Render(title)
 
@@ -229,8 +242,134 @@ func renderDashHeader(m DashboardModel, width int) string {
 	return headerStyle.Render(content)
 }
 
+// renderStationTabs renders a tab per station, numbered for the keys that
+// select them, with the active one highlighted.
+func renderStationTabs(m DashboardModel, width int) string {
+	t := currentTheme()
+	activeStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(t.Text)).Reverse(true).Bold(true)
+	tabStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(t.Label))
+	offlineStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(t.Alert))
+
+	var tabs []string
+	for i, st := range m.stations {
+		label := st.name
+		if i < 9 {
+			label = fmt.Sprintf("%d %s", i+1, st.name)
+		}
+		tab := tabStyle.Render(" " + label + " ")
+		switch {
+		case i == m.active:
+			tab = activeStyle.Render(" " + label + " ")
+		case m.isOffline(st):
+			tab = offlineStyle.Render(" " + label + " ")
+		}
+		tabs = append(tabs, tab)
+	}
+	return lipgloss.NewStyle().MaxWidth(width).Render(" " + strings.Join(tabs, " "))
+}
+
+// stationTileWidth is the narrowest a grid tile gets before the grid drops
+// a column.
+const stationTileWidth = 38
+
+// renderStationGrid renders every station as a compact tile, as many to a
+// row as fit. Tiles in a row are the same height.
+func renderStationGrid(m DashboardModel, width int) string {
+	cols := max(1, min(len(m.stations), width/stationTileWidth))
+	tileWidth := width / cols
+
+	var rows []string
+	for i := 0; i < len(m.stations); i += cols {
+		var contents []string
+		height := 0
+		for j := i; j < min(i+cols, len(m.stations)); j++ {
+			c := renderStationTile(m, j, tileWidth)
+			contents = append(contents, c)
+			height = max(height, lipgloss.Height(c))
+		}
+		tiles := make([]string, len(contents))
+		for k, c := range contents {
+			border, color := lipgloss.RoundedBorder(), getWeatherTheme(inferWeatherIcon(m.stations[i+k].currentObs)).Secondary
+			if i+k == m.active {
+				border, color = lipgloss.ThickBorder(), currentTheme().Focus
+			}
+			tiles[k] = lipgloss.NewStyle().
+				Width(tileWidth - 2).
+				Height(height).
+				BorderStyle(border).
+				BorderForeground(lipgloss.Color(color)).
+				Padding(0, 1).
+				Render(c)
+		}
+		rows = append(rows, lipgloss.JoinHorizontal(lipgloss.Top, tiles...))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, rows...)
+}
+
+// renderStationTile renders the headline values of station i, for a tile
+// of the given width.
+func renderStationTile(m DashboardModel, i, width int) string {
+	st := m.stations[i]
+	theme := getWeatherTheme(inferWeatherIcon(st.currentObs))
+	prefs := m.units
+	lineStyle := lipgloss.NewStyle().MaxWidth(width - 4)
+
+	titleStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Text)).Bold(true)
+	labelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Label))
+	valueStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Text))
+
+	title := st.name
+	if i < 9 {
+		title = fmt.Sprintf("%d %s", i+1, st.name)
+	}
+	title = titleStyle.Render(title)
+	if m.isOffline(st) {
+		title += " " + lipgloss.NewStyle().Foreground(lipgloss.Color(currentTheme().Alert)).Bold(true).Render("OFFLINE")
+	}
+	lines := []string{title}
+
+	if obs := st.currentObs; obs == nil {
+		lines = append(lines, labelStyle.Render("Waiting for observation data..."))
+	} else {
+		pressure := prefs.FormatPressure(units.Pressure(obs.Pressure))
+		if obs.Summary != nil {
+			if arrow, ok := pressureTrendArrows[obs.Summary.PressureTrend]; ok {
+				pressure += " " + arrow
+			}
+		}
+		lines = append(lines,
+			lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Primary)).Bold(true).Render(prefs.FormatTemp(units.Temperature(obs.Temperature)))+
+				"  "+valueStyle.Render(inferConditionName(obs)),
+			labelStyle.Render("Hum ")+valueStyle.Render(fmt.Sprintf("%.0f%%", obs.Humidity))+
+				labelStyle.Render("  Wind ")+valueStyle.Render(prefs.FormatSpeed(units.Speed(obs.WindAvg))+" "+degreesToCardinal(obs.WindDirection)),
+			labelStyle.Render("Press ")+valueStyle.Render(pressure)+
+				labelStyle.Render("  Rain ")+valueStyle.Render(prefs.FormatPrecip(units.Precip(obs.DailyRain))),
+		)
+	}
+
+	if len(st.events) > 0 {
+		evt := st.events[0]
+		style := valueStyle
+		if color, ok := severityColor(evt.Severity); ok {
+			style = lipgloss.NewStyle().Foreground(lipgloss.Color(color))
+		}
+		lines = append(lines, labelStyle.Render(evt.Timestamp.Format("15:04")+" ")+style.Render(evt.Describe(prefs)))
+	} else {
+		lines = append(lines, labelStyle.Render("No recent events"))
+	}
+	if !st.lastUpdate.IsZero() {
+		lines = append(lines, labelStyle.Render(fmt.Sprintf("Updated %s ago", time.Since(st.lastUpdate).Truncate(time.Second))))
+	}
+
+	for i, l := range lines {
+		lines[i] = lineStyle.Render(l)
+	}
+	return strings.Join(lines, "\n")
+}
+
 func renderConditionsPanel(m DashboardModel, width int) string {
-	theme := getWeatherTheme(inferWeatherIcon(m.currentObs))
+	st := m.station()
+	theme := getWeatherTheme(inferWeatherIcon(st.currentObs))
 
 	panelStyle := lipgloss.NewStyle().
 		Width(width - 2).
@@ -238,7 +377,7 @@ func renderConditionsPanel(m DashboardModel, width int) string {
 		BorderForeground(lipgloss.Color(theme.Secondary)).
 		Padding(1, 2)
 
-	if m.currentObs == nil {
+	if st.currentObs == nil {
 		waitMsg := "Waiting for observation data..."
 		if m.errMsg != "" {
 			waitMsg = fmt.Sprintf("Error: %s", m.errMsg)
@@ -246,8 +385,8 @@ func renderConditionsPanel(m DashboardModel, width int) string {
 		return panelStyle.Render(waitMsg)
 	}
 
-	obs := m.currentObs
-	prefs := m.unitPrefs
+	obs := st.currentObs
+	prefs := m.units
 	iconKey := inferWeatherIcon(obs)
 	icon := getWeatherIcon(iconKey)
 
@@ -257,46 +396,103 @@ func renderConditionsPanel(m DashboardModel, width int) string {
 	iconBlock := iconStyle.Render(strings.Join(icon.Full, "\n"))
 
 	labelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Label))
-	valueStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#FFFFFF"))
-	tempStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#FFFFFF")).Bold(true)
+	valueStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Text))
+	tempStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Text)).Bold(true)
 
-	temp := convertTemp(obs.Temperature, prefs)
-	windSpeed := convertWind(obs.WindAvg, prefs)
-	rain := convertPrecip(obs.DailyRain, prefs)
-	dewPoint := convertTemp(obs.Temperature-(100-obs.Humidity)/5, prefs) // approximation
+	sum := obs.Summary
+	if sum == nil {
+		sum = &ObsSummary{}
+	}
 
 	condName := inferConditionName(obs)
 
-	lines := []string{
-		fmt.Sprintf("%s", tempStyle.Render(fmt.Sprintf("%.1f\u00b0%s", temp, tempUnitLabel(prefs)))),
-		valueStyle.Render(condName),
-		"",
-		fmt.Sprintf("%s %s       %s %s",
-			labelStyle.Render("Humidity:"),
-			valueStyle.Render(fmt.Sprintf("%.0f%%", obs.Humidity)),
-			labelStyle.Render("Wind:"),
-			valueStyle.Render(fmt.Sprintf("%.1f %s %s", windSpeed, windUnitLabel(prefs), degreesToCardinal(obs.WindDirection))),
-		),
-		fmt.Sprintf("%s %s      %s %s",
-			labelStyle.Render("Pressure:"),
-			valueStyle.Render(fmt.Sprintf("%.0f mb", obs.Pressure)),
-			labelStyle.Render("UV:"),
-			valueStyle.Render(fmt.Sprintf("%.0f", obs.UV)),
-		),
-		fmt.Sprintf("%s %s    %s %s",
-			labelStyle.Render("Dew Point:"),
-			valueStyle.Render(fmt.Sprintf("%.1f\u00b0%s", dewPoint, tempUnitLabel(prefs))),
-			labelStyle.Render("Rain:"),
-			valueStyle.Render(fmt.Sprintf("%.1f %s", rain, precipUnitLabel(prefs))),
-		),
+	tempLine := tempStyle.Render(prefs.FormatTemp(units.Temperature(obs.Temperature)))
+	if sum.FeelsLike != nil {
+		tempLine += "  " + labelStyle.Render("Feels like") + " " + valueStyle.Render(prefs.FormatTemp(units.Temperature(*sum.FeelsLike)))
 	}
 
+	pressure := prefs.FormatPressure(units.Pressure(obs.Pressure))
+	if arrow, ok := pressureTrendArrows[sum.PressureTrend]; ok {
+		pressure += " " + arrow + " " + sum.PressureTrend
+	}
+
+	// Without the server's value, use the Magnus approximation.
+	dewPoint := magnusDewPoint(obs.Temperature, obs.Humidity)
+	if sum.DewPoint != nil {
+		dewPoint = *sum.DewPoint
+	}
+	dewPointText := "--"
+	if !math.IsNaN(dewPoint) {
+		dewPointText = prefs.FormatTemp(units.Temperature(dewPoint))
+	}
+
+	rows := [][2][2]string{
+		{{"Humidity:", fmt.Sprintf("%.0f%%", obs.Humidity)}, {"Wind:", prefs.FormatSpeed(units.Speed(obs.WindAvg)) + " " + degreesToCardinal(obs.WindDirection)}},
+		{{"Pressure:", pressure}, {"UV:", fmt.Sprintf("%.0f", obs.UV)}},
+		{{"Dew Point:", dewPointText}, {"Rain:", prefs.FormatPrecip(units.Precip(obs.DailyRain))}},
+	}
+	var extra [][2]string
+	if sum.StrikeCount1h != nil && sum.StrikeCount3h != nil {
+		extra = append(extra, [2]string{"Strikes:", fmt.Sprintf("%d (1h) %d (3h)", *sum.StrikeCount1h, *sum.StrikeCount3h)})
+	}
+	if sum.PrecipTotal1h != nil {
+		extra = append(extra, [2]string{"Rain 1h:", prefs.FormatPrecip(units.Precip(*sum.PrecipTotal1h))})
+	}
+	if len(extra) == 1 {
+		extra = append(extra, [2]string{})
+	}
+	if len(extra) == 2 {
+		rows = append(rows, [2][2]string{extra[0], extra[1]})
+	}
+
+	lines := []string{tempLine, valueStyle.Render(condName), ""}
+	lines = append(lines, statColumns(rows, labelStyle, valueStyle)...)
+
 	stats := strings.Join(lines, "\n")
 	content := lipgloss.JoinHorizontal(lipgloss.Top, iconBlock, "   ", stats)
 
 	return panelStyle.Render(content)
 }
 
+// pressureTrendArrows maps the pressure_trend of an obs_st summary to an arrow.
+var pressureTrendArrows = map[string]string{
+	"rising":  "↑",
+	"falling": "↓",
+	"steady":  "→",
+}
+
+// magnusDewPoint approximates the dew point in °C from the temperature in °C
+// and relative humidity in percent. It returns NaN when humidity is unknown.
+func magnusDewPoint(tempC, humidity float64) float64 {
+	const b, c = 17.625, 243.04
+	if humidity <= 0 {
+		return math.NaN()
+	}
+	g := math.Log(humidity/100) + b*tempC/(c+tempC)
+	return c * g / (b - g)
+}
+
+// statColumns lays out rows of two "label value" pairs with the second
+// pair of every row aligned.
+func statColumns(rows [][2][2]string, labelStyle, valueStyle lipgloss.Style) []string {
+	render := func(p [2]string) string {
+		if p[0] == "" {
+			return ""
+		}
+		return labelStyle.Render(p[0]) + " " + valueStyle.Render(p[1])
+	}
+	left := 0
+	for _, r := range rows {
+		left = max(left, lipgloss.Width(render(r[0])))
+	}
+	lines := make([]string, len(rows))
+	for i, r := range rows {
+		l := render(r[0])
+		lines[i] = l + strings.Repeat(" ", left-lipgloss.Width(l)+4) + render(r[1])
+	}
+	return lines
+}
+
 func inferConditionName(obs *ObsData) string {
 	if obs == nil {
 		return "Unknown"
@@ -323,7 +519,8 @@ func inferConditionName(obs *ObsData) string {
 }
 
 func renderWindPanel(m DashboardModel, width int) string {
-	theme := getWeatherTheme(inferWeatherIcon(m.currentObs))
+	st := m.station()
+	theme := getWeatherTheme(inferWeatherIcon(st.currentObs))
 
 	panelStyle := lipgloss.NewStyle().
 		Width(width - 2).
@@ -332,46 +529,42 @@ func renderWindPanel(m DashboardModel, width int) string {
 		Padding(0, 2)
 
 	labelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Label))
-	valueStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#FFFFFF"))
-	headerStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#FFFFFF")).Bold(true)
+	valueStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Text))
+	headerStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Text)).Bold(true)
 
-	prefs := m.unitPrefs
+	prefs := m.units
 
 	windDir := 0
-	windSpeed := 0.0
-	windGust := 0.0
-	windLull := 0.0
-	if m.currentObs != nil {
-		windDir = m.currentObs.WindDirection
-		windSpeed = convertWind(m.currentObs.WindAvg, prefs)
-		windGust = convertWind(m.currentObs.WindGust, prefs)
-		windLull = convertWind(m.currentObs.WindLull, prefs)
+	var windSpeed, windGust, windLull units.Speed
+	if st.currentObs != nil {
+		windDir = st.currentObs.WindDirection
+		windSpeed = units.Speed(st.currentObs.WindAvg)
+		windGust = units.Speed(st.currentObs.WindGust)
+		windLull = units.Speed(st.currentObs.WindLull)
 	}
 	// Override with rapid wind if available
-	if m.rapidWind != nil {
-		windDir = m.rapidWind.WindDirection
-		windSpeed = convertWind(m.rapidWind.WindSpeed, prefs)
+	if st.rapidWind != nil {
+		windDir = st.rapidWind.WindDirection
+		windSpeed = units.Speed(st.rapidWind.WindSpeed)
 	}
 
-	wUnit := windUnitLabel(prefs)
-
 	compass := renderWindCompass(windDir)
 
 	statsLines := []string{
 		fmt.Sprintf("%s %s   %s %s",
 			labelStyle.Render("Speed:"),
-			valueStyle.Render(fmt.Sprintf("%.1f %s", windSpeed, wUnit)),
+			valueStyle.Render(prefs.FormatSpeed(windSpeed)),
 			labelStyle.Render("Gust:"),
-			valueStyle.Render(fmt.Sprintf("%.1f %s", windGust, wUnit)),
+			valueStyle.Render(prefs.FormatSpeed(windGust)),
 		),
 		fmt.Sprintf("%s  %s   %s  %s",
 			labelStyle.Render("Lull:"),
-			valueStyle.Render(fmt.Sprintf("%.1f %s", windLull, wUnit)),
+			valueStyle.Render(prefs.FormatSpeed(windLull)),
 			labelStyle.Render("Dir:"),
 			valueStyle.Render(fmt.Sprintf("%s %d\u00b0", degreesToCardinal(windDir), windDir)),
 		),
 		"",
-		valueStyle.Render(renderWindSparkline(m.windHistory)),
+		valueStyle.Render(renderWindSparkline(st.windHistory, width-windStatsOffset)),
 	}
 
 	compassBlock := lipgloss.NewStyle().Render(compass)
@@ -384,30 +577,60 @@ func renderWindPanel(m DashboardModel, width int) string {
 	return panelStyle.Render(content)
 }
 
-func renderEventsPanel(m DashboardModel, width int) string {
-	theme := getWeatherTheme(inferWeatherIcon(m.currentObs))
+// severityColor returns the theme's color for events above SeverityInfo.
+func severityColor(sev EventSeverity) (string, bool) {
+	switch sev {
+	case SeverityWarning:
+		return currentTheme().Warning, true
+	case SeverityAlert:
+		return currentTheme().Alert, true
+	}
+	return "", false
+}
+
+// renderEventsPanel renders as many of the most recent events as fit in a
+// panel height rows tall, border included, filling it. With a height of 0
+// it shows up to ten.
+func renderEventsPanel(m DashboardModel, width, height int) string {
+	st := m.station()
+	theme := getWeatherTheme(inferWeatherIcon(st.currentObs))
 
 	panelStyle := lipgloss.NewStyle().
 		Width(width - 2).
-		BorderStyle(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color(theme.Secondary)).
+		BorderStyle(m.border(panelEvents)).
+		BorderForeground(m.borderColor(panelEvents, theme)).
 		Padding(0, 2)
+	rows := 10
+	if height > 0 {
+		rows = max(1, height-3)
+		panelStyle = panelStyle.Height(rows + 1)
+	}
 
-	headerStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#FFFFFF")).Bold(true)
+	headerStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Text)).Bold(true)
 	labelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Label))
-	valueStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#FFFFFF"))
+	valueStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Text))
 
 	header := headerStyle.Render("  EVENTS")
 
-	if len(m.events) == 0 {
+	if len(st.events) == 0 {
 		content := lipgloss.JoinVertical(lipgloss.Left, header, labelStyle.Render("  No recent events"))
 		return panelStyle.Render(content)
 	}
 
+	first := min(m.eventScroll, len(st.events)-1)
+	shown := st.events[first:min(len(st.events), first+max(rows, 1))]
+	if len(shown) < len(st.events) {
+		header += labelStyle.Render(fmt.Sprintf("   %d-%d of %d", first+1, first+len(shown), len(st.events)))
+	}
+
 	var lines []string
-	for _, evt := range m.events {
+	for _, evt := range shown {
 		ts := evt.Timestamp.Format("15:04")
-		line := fmt.Sprintf("  %s  %s", labelStyle.Render(ts), valueStyle.Render(evt.Detail))
+		style := valueStyle
+		if color, ok := severityColor(evt.Severity); ok {
+			style = lipgloss.NewStyle().Foreground(lipgloss.Color(color))
+		}
+		line := fmt.Sprintf("  %s  %s", labelStyle.Render(ts), style.Render(evt.Describe(m.units)))
 		lines = append(lines, line)
 	}
 
@@ -416,24 +639,51 @@ func renderEventsPanel(m DashboardModel, width int) string {
 }
 
 func renderStatusBar(m DashboardModel, width int) string {
-	labelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#888888"))
+	labelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(currentTheme().Label))
 
+	st := m.station()
 	var leftText string
-	if m.lastUpdate.IsZero() {
+	if lastUpdate := m.lastUpdate(); lastUpdate.IsZero() {
 		leftText = "  Waiting for data..."
 	} else {
-		ago := time.Since(m.lastUpdate).Truncate(time.Second)
+		ago := time.Since(lastUpdate).Truncate(time.Second)
 		leftText = fmt.Sprintf("  Last updated: %s ago", ago)
 	}
 
+	if !m.grid && st.deviceStatus != nil && st.deviceStatus.Voltage > 0 {
+		leftText += fmt.Sprintf(" | Battery %.2fV", st.deviceStatus.Voltage)
+	}
+	if len(m.offline) > 0 {
+		offline := make([]string, 0, len(m.offline))
+		for k := range m.offline {
+			offline = append(offline, k)
+		}
+		sort.Strings(offline)
+		leftText += " | OFFLINE: " + strings.Join(offline, ", ")
+	}
 	if m.reconnecting {
-		leftText += fmt.Sprintf(" (reconnecting %d/5...)", m.reconnectAttempts)
+		leftText += fmt.Sprintf(" (reconnecting, attempt %d...)", m.reconnectAttempts+1)
 	}
 	if m.errMsg != "" && !m.reconnecting {
 		leftText += fmt.Sprintf(" | Error: %s", m.errMsg)
 	}
+	if m.paused {
+		leftText += fmt.Sprintf(" | Paused (%d held)", len(m.held))
+	}
+	if m.notice != "" && time.Since(m.noticeAt) < noticeDuration {
+		leftText += " | " + m.notice
+	}
 
-	rightText := "Press q to quit  "
+	hint := func(action keyAction, what string) string {
+		if key := m.keys.first(action); key != "" {
+			return key + ": " + what + "  "
+		}
+		return ""
+	}
+	rightText := hint(actHelp, "keys") + hint(actQuit, "quit")
+	if len(m.stations) > 1 {
+		rightText = hint(actNextStation, "station") + hint(actGrid, "grid") + rightText
+	}
 
 	innerWidth := width
 	leftLen := lipgloss.Width(leftText)
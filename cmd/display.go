@@ -7,6 +7,9 @@ import (
 	"strings"
 	"time"
 
+	"tempest-cli/tempest"
+	"tempest-cli/units"
+
 	"github.com/charmbracelet/lipgloss"
 	"golang.org/x/term"
 )
@@ -21,7 +24,7 @@ func getTerminalWidth() int {
 }
 
 // RenderForecast is the main entry point for the styled weather display.
-func RenderForecast(f Forecast) {
+func RenderForecast(f tempest.Forecast, prefs units.Prefs) {
 	width := getTerminalWidth()
 	if width > 80 {
 		width = 80
@@ -30,8 +33,8 @@ func RenderForecast(f Forecast) {
 	theme := getWeatherTheme(f.CurrentConditions.Icon)
 
 	header := renderHeader(f, width, theme)
-	current := renderCurrentConditions(f, width, theme)
-	daily := renderDailyForecast(f, width, theme)
+	current := renderCurrentConditions(f, prefs, width, theme)
+	daily := renderDailyForecast(f, prefs, width, theme)
 
 	fmt.Println(header)
 	fmt.Println(current)
@@ -39,12 +42,12 @@ func RenderForecast(f Forecast) {
 }
 
 // renderHeader creates the location/timezone banner.
-func renderHeader(f Forecast, width int, theme WeatherTheme) string {
+func renderHeader(f tempest.Forecast, width int, theme WeatherTheme) string {
 	headerStyle := lipgloss.NewStyle().
 		Width(width - 2).
 		Align(lipgloss.Center).
 		Bold(true).
-		Foreground(lipgloss.Color("#FFFFFF")).
+		Foreground(lipgloss.Color(theme.Text)).
 		BorderStyle(lipgloss.RoundedBorder()).
 		BorderForeground(lipgloss.Color(theme.Secondary)).
 		Padding(0, 1)
@@ -54,7 +57,7 @@ func renderHeader(f Forecast, width int, theme WeatherTheme) string {
 }
 
 // renderCurrentConditions creates the main weather panel with icon + stats.
-func renderCurrentConditions(f Forecast, width int, theme WeatherTheme) string {
+func renderCurrentConditions(f tempest.Forecast, prefs units.Prefs, width int, theme WeatherTheme) string {
 	icon := getWeatherIcon(f.CurrentConditions.Icon)
 
 	iconStyle := lipgloss.NewStyle().
@@ -62,7 +65,7 @@ func renderCurrentConditions(f Forecast, width int, theme WeatherTheme) string {
 
 	iconBlock := iconStyle.Render(strings.Join(icon.Full, "\n"))
 
-	stats := renderCurrentStats(f, theme)
+	stats := renderCurrentStats(f, prefs, theme)
 
 	// Lay out icon on the left and stats on the right
 	content := lipgloss.JoinHorizontal(lipgloss.Top, iconBlock, "   ", stats)
@@ -77,21 +80,17 @@ func renderCurrentConditions(f Forecast, width int, theme WeatherTheme) string {
 }
 
 // renderCurrentStats builds the text block of weather statistics.
-func renderCurrentStats(f Forecast, theme WeatherTheme) string {
+func renderCurrentStats(f tempest.Forecast, prefs units.Prefs, theme WeatherTheme) string {
 	cc := f.CurrentConditions
-	u := f.Units
 
 	labelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Label))
-	valueStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#FFFFFF"))
-	tempStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#FFFFFF")).Bold(true)
-
-	tempUnit := tempUnitSymbol(u.UnitsTemp)
-	windUnit := u.UnitsWind
+	valueStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Text))
+	tempStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Text)).Bold(true)
 
 	lines := []string{
 		fmt.Sprintf("%s  %s",
-			tempStyle.Render(formatTemp(cc.AirTemperature, tempUnit)),
-			labelStyle.Render(fmt.Sprintf("Feels like %s", formatTemp(cc.FeelsLike, tempUnit))),
+			tempStyle.Render(prefs.FormatTempRound(units.Temperature(cc.AirTemperature))),
+			labelStyle.Render(fmt.Sprintf("Feels like %s", prefs.FormatTempRound(units.Temperature(cc.FeelsLike)))),
 		),
 		valueStyle.Render(cc.Conditions),
 		"",
@@ -99,17 +98,17 @@ func renderCurrentStats(f Forecast, theme WeatherTheme) string {
 			labelStyle.Render("Humidity:"),
 			valueStyle.Render(fmt.Sprintf("%d%%", cc.RelativeHumidity)),
 			labelStyle.Render("Wind:"),
-			valueStyle.Render(fmt.Sprintf("%.1f %s %s", cc.WindAvg, windUnit, cc.WindDirectionCardinal)),
+			valueStyle.Render(prefs.FormatSpeed(units.Speed(cc.WindAvg))+" "+cc.WindDirectionCardinal),
 		),
 		fmt.Sprintf("%s %s      %s %s",
 			labelStyle.Render("Pressure:"),
-			valueStyle.Render(formatPressure(cc.SeaLevelPressure, u.UnitsPressure)),
+			valueStyle.Render(prefs.FormatPressure(units.Pressure(cc.SeaLevelPressure))),
 			labelStyle.Render("UV:"),
 			valueStyle.Render(fmt.Sprintf("%d", cc.Uv)),
 		),
 		fmt.Sprintf("%s %s    %s %s",
 			labelStyle.Render("Dew Point:"),
-			valueStyle.Render(formatTemp(cc.DewPoint, tempUnit)),
+			valueStyle.Render(prefs.FormatTempRound(units.Temperature(cc.DewPoint))),
 			labelStyle.Render("Precip:"),
 			valueStyle.Render(fmt.Sprintf("%d%%", cc.PrecipProbability)),
 		),
@@ -119,7 +118,7 @@ func renderCurrentStats(f Forecast, theme WeatherTheme) string {
 }
 
 // renderDailyForecast creates the multi-day forecast panel.
-func renderDailyForecast(f Forecast, width int, theme WeatherTheme) string {
+func renderDailyForecast(f tempest.Forecast, prefs units.Prefs, width int, theme WeatherTheme) string {
 	days := f.Forecast.Daily
 	maxCards := 5
 	if width < 60 {
@@ -140,7 +139,7 @@ func renderDailyForecast(f Forecast, width int, theme WeatherTheme) string {
 
 	var cards []string
 	for i := start; i < end; i++ {
-		cards = append(cards, renderDailyCard(days[i], f.Units, theme))
+		cards = append(cards, renderDailyCard(days[i], prefs, theme))
 	}
 
 	content := lipgloss.JoinHorizontal(lipgloss.Top, cards...)
@@ -156,20 +155,19 @@ func renderDailyForecast(f Forecast, width int, theme WeatherTheme) string {
 }
 
 // renderDailyCard builds a single day's forecast card.
-func renderDailyCard(day ForecastDaily, units ForecastUnits, theme WeatherTheme) string {
+func renderDailyCard(day tempest.ForecastDaily, prefs units.Prefs, theme WeatherTheme) string {
 	icon := getWeatherIcon(day.Icon)
 	iconStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(getWeatherTheme(day.Icon).Primary))
 	labelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Label))
-	valueStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#FFFFFF"))
+	valueStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Text))
 
-	tempUnit := tempUnitSymbol(units.UnitsTemp)
 	dayStr := dayName(day.DayStartLocal)
 
 	miniArt := iconStyle.Render(strings.Join(icon.Mini, "\n"))
 
 	temps := valueStyle.Render(fmt.Sprintf("%s/%s",
-		formatTempShort(day.AirTempHigh, tempUnit),
-		formatTempShort(day.AirTempLow, tempUnit),
+		formatTempShort(units.Temperature(day.AirTempHigh), prefs),
+		formatTempShort(units.Temperature(day.AirTempLow), prefs),
 	))
 
 	conditions := labelStyle.Render(day.Conditions)
@@ -197,25 +195,13 @@ func renderDailyCard(day ForecastDaily, units ForecastUnits, theme WeatherTheme)
 
 // --- Helpers ---
 
-func tempUnitSymbol(unit string) string {
-	switch unit {
-	case "f":
-		return "F"
-	default:
-		return "C"
+// formatTempShort formats a temperature without its unit, for compact cards.
+func formatTempShort(temp units.Temperature, prefs units.Prefs) string {
+	deg := "°"
+	if prefs.Temp == units.Kelvin {
+		deg = ""
 	}
-}
-
-func formatTemp(temp float64, unit string) string {
-	return fmt.Sprintf("%.0f°%s", math.Round(temp), unit)
-}
-
-func formatTempShort(temp float64, unit string) string {
-	return fmt.Sprintf("%.0f°", math.Round(temp))
-}
-
-func formatPressure(p float64, unit string) string {
-	return fmt.Sprintf("%.0f %s", p, unit)
+	return fmt.Sprintf("%.0f%s", math.Round(temp.In(prefs.Temp)), deg)
 }
 
 func dayName(epoch int) string {
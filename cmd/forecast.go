@@ -4,135 +4,17 @@ Copyright © 2025 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
-	"io/ioutil"
-	"log"
-	"net/http"
-	"net/url"
-	"os"
+	"slices"
+	"strings"
+
+	"tempest-cli/tempest"
+	"tempest-cli/units"
 
-	"github.com/joho/godotenv"
 	"github.com/spf13/cobra"
 )
 
-type ForecastCurrentConditions struct {
-	AirDensity                      float64 `json:"air_density"`
-	AirTemperature                  float64 `json:"air_temperature"`
-	Brightness                      int     `json:"brightness"`
-	Conditions                      string  `json:"conditions"`
-	DeltaT                          float64 `json:"delta_t"`
-	DewPoint                        float64 `json:"dew_point"`
-	FeelsLike                       float64 `json:"feels_like"`
-	Icon                            string  `json:"icon"`
-	IsPrecipLocalDayRainCheck       bool    `json:"is_precip_local_day_rain_check"`
-	IsPrecipLocalYesterdayRainCheck bool    `json:"is_precip_local_yesterday_rain_check"`
-	LightningStrikeCountLast1Hr     int     `json:"lightning_strike_count_last_1hr"`
-	LightningStrikeCountLast3Hr     int     `json:"lightning_strike_count_last_3hr"`
-	LightningStrikeLastDistance     int     `json:"lightning_strike_last_distance"`
-	LightningStrikeLastDistanceMsg  string  `json:"lightning_strike_last_distance_msg"`
-	LightningStrikeLastEpoch        int     `json:"lightning_strike_last_epoch"`
-	PrecipAccumLocalDay             int     `json:"precip_accum_local_day"`
-	PrecipAccumLocalYesterday       int     `json:"precip_accum_local_yesterday"`
-	PrecipMinutesLocalDay           int     `json:"precip_minutes_local_day"`
-	PrecipMinutesLocalYesterday     int     `json:"precip_minutes_local_yesterday"`
-	PrecipProbability               int     `json:"precip_probability"`
-	PressureTrend                   string  `json:"pressure_trend"`
-	RelativeHumidity                int     `json:"relative_humidity"`
-	SeaLevelPressure                float64 `json:"sea_level_pressure"`
-	SolarRadiation                  int     `json:"solar_radiation"`
-	StationPressure                 float64 `json:"station_pressure"`
-	Time                            int     `json:"time"`
-	Uv                              int     `json:"uv"`
-	WetBulbGlobeTemperature         float64 `json:"wet_bulb_globe_temperature"`
-	WetBulbTemperature              float64 `json:"wet_bulb_temperature"`
-	WindAvg                         float64 `json:"wind_avg"`
-	WindDirection                   int     `json:"wind_direction"`
-	WindDirectionCardinal           string  `json:"wind_direction_cardinal"`
-	WindGust                        float64 `json:"wind_gust"`
-}
-
-type ForecastDaily struct {
-	AirTempHigh       float64 `json:"air_temp_high"`
-	AirTempLow        float64 `json:"air_temp_low"`
-	Conditions        string  `json:"conditions"`
-	DayNum            int     `json:"day_num"`
-	DayStartLocal     int     `json:"day_start_local"`
-	Icon              string  `json:"icon"`
-	MonthNum          int     `json:"month_num"`
-	PrecipIcon        string  `json:"precip_icon"`
-	PrecipProbability int     `json:"precip_probability"`
-	PrecipType        string  `json:"precip_type"`
-	Sunrise           int     `json:"sunrise"`
-	Sunset            int     `json:"sunset"`
-}
-
-type ForecastHourly struct {
-	AirTemperature        float64 `json:"air_temperature"`
-	Conditions            string  `json:"conditions"`
-	FeelsLike             float64 `json:"feels_like"`
-	Icon                  string  `json:"icon"`
-	LocalDay              int     `json:"local_day"`
-	LocalHour             int     `json:"local_hour"`
-	Precip                float64 `json:"precip"`
-	PrecipIcon            string  `json:"precip_icon"`
-	PrecipProbability     int     `json:"precip_probability"`
-	PrecipType            string  `json:"precip_type"`
-	RelativeHumidity      int     `json:"relative_humidity"`
-	SeaLevelPressure      float64 `json:"sea_level_pressure"`
-	StationPressure       float64 `json:"station_pressure"`
-	Time                  int     `json:"time"`
-	Uv                    float64 `json:"uv"`
-	WindAvg               float64 `json:"wind_avg"`
-	WindDirection         int     `json:"wind_direction"`
-	WindDirectionCardinal string  `json:"wind_direction_cardinal"`
-	WindGust              float64 `json:"wind_gust"`
-}
-
-type ForecastUnits struct {
-	UnitsAirDensity     string `json:"units_air_density"`
-	UnitsBrightness     string `json:"units_brightness"`
-	UnitsDistance       string `json:"units_distance"`
-	UnitsOther          string `json:"units_other"`
-	UnitsPrecip         string `json:"units_precip"`
-	UnitsPressure       string `json:"units_pressure"`
-	UnitsSolarRadiation string `json:"units_solar_radiation"`
-	UnitsTemp           string `json:"units_temp"`
-	UnitsWind           string `json:"units_wind"`
-}
-
-type Forecast struct {
-	CurrentConditions ForecastCurrentConditions `json:"current_conditions"`
-	Forecast          struct {
-		Daily  []ForecastDaily  `json:"daily"`
-		Hourly []ForecastHourly `json:"hourly"`
-	} `json:"forecast"`
-	Latitude           float64 `json:"latitude"`
-	LocationName       string  `json:"location_name"`
-	Longitude          float64 `json:"longitude"`
-	SourceIDConditions int     `json:"source_id_conditions"`
-	Station            struct {
-		Agl             float64 `json:"agl"`
-		Elevation       float64 `json:"elevation"`
-		IsStationOnline bool    `json:"is_station_online"`
-		State           int     `json:"state"`
-		StationID       int     `json:"station_id"`
-	} `json:"station"`
-	Status struct {
-		StatusCode    int    `json:"status_code"`
-		StatusMessage string `json:"status_message"`
-	} `json:"status"`
-	Timezone              string        `json:"timezone"`
-	TimezoneOffsetMinutes int           `json:"timezone_offset_minutes"`
-	Units                 ForecastUnits `json:"units"`
-}
-
-var (
-	temperatureAsFahrenheit bool
-	distanceAsMiles         bool
-	precipAsInches          bool
-	windAsMph               bool
-)
+var forecastRows string
 
 // forecastCmd represents the forecast command
 var forecastCmd = &cobra.Command{
@@ -140,71 +22,101 @@ var forecastCmd = &cobra.Command{
 	Short: "Given a station ID, return forecoast",
 	Long: `Forecast data is available for Tempest stations. Given a specific API token and station id you will be returned with
 	forestcast data. `,
-	Run: func(cmd *cobra.Command, args []string) {
-		apiToken := getAPIToken()
-		sid := cmd.Flag("station").Value.String()
-		baseURL := "https://swd.weatherflow.com/swd/rest/better_forecast"
-		params := url.Values{}
-		params.Add("station_id", sid)
-		params.Add("token", apiToken)
-		if temperatureAsFahrenheit {
-			params.Add("units_temp", "f")
-		}
-		if distanceAsMiles {
-			params.Add("units_distance", "mi")
-		}
-		if precipAsInches {
-			params.Add("units_precip", "in")
-		}
-		if windAsMph {
-			params.Add("units_wind", "mph")
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sid, err := stationIDFlag(cmd)
+		if err != nil {
+			return err
 		}
-		req, err := http.NewRequest("GET", baseURL, nil)
+		rowsOf, err := forecastRowSelector(forecastRows)
 		if err != nil {
-			fmt.Println("Error creating request:", err)
-			return
+			return err
 		}
-		req.URL.RawQuery = params.Encode()
-		resp, err := http.DefaultClient.Do(req)
+		// Always fetch metric and convert locally, so the forecast is shown
+		// in the same units as every other command.
+		units := tempest.ForecastUnitOptions{Temp: "c", Wind: "mps", Pressure: "mb", Precip: "mm", Distance: "km"}
+
+		ctx, info := withResponseInfo(cmd.Context())
+		client, err := newAPIClient()
 		if err != nil {
-			fmt.Println("Error making request:", err)
-			return
+			return err
 		}
-		defer resp.Body.Close()
-		body, err := ioutil.ReadAll(resp.Body)
+		f, err := client.GetBetterForecast(ctx, sid, units)
 		if err != nil {
-			fmt.Println("Error reading response body:", err)
-			return
+			return fmt.Errorf("fetching forecast: %w", err)
 		}
+		reportStale(info)
 
-		if cmd.Flag("output").Value.String() == "JSON" {
-			fmt.Println(string(body))
-		} else {
-			var f Forecast
-			err := json.Unmarshal(body, &f)
-			if err != nil {
-				log.Fatalf("Error unmarshaling JSON: %s", err)
-			}
-			RenderForecast(f)
+		if outputFormat != nil {
+			f := forecastIn(*f, displayUnits)
+			return writeResult(f, rowsOf(&f))
 		}
-
+		RenderForecast(*f, displayUnits)
+		return nil
 	},
 }
 
-func getAPIToken() string {
-	err := godotenv.Load(".env")
-	if err != nil {
-		log.Fatalf("Error loading .env file: %s", err)
+// forecastIn returns a copy of a metric forecast with its measurements
+// converted to the units of u, and its units block naming them.
+func forecastIn(f tempest.Forecast, u units.Prefs) tempest.Forecast {
+	temp := func(v *float64) { *v = units.Temperature(*v).In(u.Temp) }
+	speed := func(v *float64) { *v = units.Speed(*v).In(u.Wind) }
+	pressure := func(v *float64) { *v = units.Pressure(*v).In(u.Pressure) }
+	precip := func(v *float64) { *v = units.Precip(*v).In(u.Precip) }
+
+	c := &f.CurrentConditions
+	for _, v := range []*float64{&c.AirTemperature, &c.DewPoint, &c.FeelsLike, &c.WetBulbTemperature, &c.WetBulbGlobeTemperature} {
+		temp(v)
 	}
+	speed(&c.WindAvg)
+	speed(&c.WindGust)
+	pressure(&c.SeaLevelPressure)
+	pressure(&c.StationPressure)
+	precip(&c.PrecipAccumLocalDay)
+	precip(&c.PrecipAccumLocalYesterday)
+	c.LightningStrikeLastDistance = units.Distance(c.LightningStrikeLastDistance).In(u.Distance)
 
-	return os.Getenv("API_TOKEN")
+	f.Forecast.Daily = slices.Clone(f.Forecast.Daily)
+	for i := range f.Forecast.Daily {
+		d := &f.Forecast.Daily[i]
+		temp(&d.AirTempHigh)
+		temp(&d.AirTempLow)
+	}
+	f.Forecast.Hourly = slices.Clone(f.Forecast.Hourly)
+	for i := range f.Forecast.Hourly {
+		h := &f.Forecast.Hourly[i]
+		temp(&h.AirTemperature)
+		temp(&h.FeelsLike)
+		speed(&h.WindAvg)
+		speed(&h.WindGust)
+		pressure(&h.SeaLevelPressure)
+		pressure(&h.StationPressure)
+		precip(&h.Precip)
+	}
+
+	f.Units.UnitsTemp = string(u.Temp)
+	f.Units.UnitsWind = string(u.Wind)
+	f.Units.UnitsPressure = string(u.Pressure)
+	f.Units.UnitsPrecip = string(u.Precip)
+	f.Units.UnitsDistance = string(u.Distance)
+	return f
+}
+
+// forecastRowSelector returns the part of a forecast that --rows selects for
+// tabular and template output.
+func forecastRowSelector(rows string) (func(*tempest.Forecast) any, error) {
+	switch strings.ToLower(rows) {
+	case "current":
+		return func(f *tempest.Forecast) any { return f.CurrentConditions }, nil
+	case "daily":
+		return func(f *tempest.Forecast) any { return f.Forecast.Daily }, nil
+	case "hourly":
+		return func(f *tempest.Forecast) any { return f.Forecast.Hourly }, nil
+	}
+	return nil, fmt.Errorf("invalid --rows %q, valid values are: current, daily, hourly", rows)
 }
 
 func init() {
 	rootCmd.AddCommand(forecastCmd)
 
-	forecastCmd.Flags().BoolVarP(&temperatureAsFahrenheit, "fahrenheit", "f", false, "Display temperature in Fahrenheit, Default is Celsius")
-	forecastCmd.Flags().BoolVarP(&distanceAsMiles, "miles", "", false, "Display distance in Miles, Default is Kilometers")
-	forecastCmd.Flags().BoolVarP(&precipAsInches, "inches", "", false, "Display precip in Inches Default is Millimeters")
-	forecastCmd.Flags().BoolVarP(&windAsMph, "mph", "", false, "Display wind in Miles per hour default is Kilometers per hour")
+	forecastCmd.Flags().StringVar(&forecastRows, "rows", "current", "Rows for csv, tsv, table and template output: current, daily or hourly")
 }
@@ -0,0 +1,216 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"tempest-cli/output"
+	"tempest-cli/units"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	historyDeviceID int
+	historyStart    string
+	historyEnd      string
+	historyLast     string
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Show device observations over a time range",
+	Long: `Fetch the observations a Tempest device recorded over a time range.
+
+The device is taken from --device, or looked up from the station given with
+-s the same way the live dashboard finds it. The range is given either with
+--start/--end or with --last. --end defaults to now and --start to 24 hours
+before the end. Long ranges are fetched in one-day chunks.
+
+Times accept RFC 3339 (2025-01-02T15:04:05Z), "2025-01-02 15:04" or
+"2025-01-02" in local time. --last accepts Go durations plus days, e.g. 90m,
+24h or 7d.
+
+Output is a table by default; use -o csv, -o json or any other output
+format for machine-readable output. Every format shows values in the
+selected display units; table, CSV, TSV and template columns are named
+after them.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		start, end, err := historyRange(time.Now())
+		if err != nil {
+			return err
+		}
+
+		client, err := newAPIClient()
+		if err != nil {
+			return err
+		}
+		ctx, info := withResponseInfo(cmd.Context())
+
+		deviceID := historyDeviceID
+		if deviceID == 0 {
+			sid, err := stationIDFlag(cmd)
+			if err != nil {
+				return fmt.Errorf("%w (or give a device ID with --device)", err)
+			}
+			station, err := client.GetStation(ctx, sid)
+			if err != nil {
+				return fmt.Errorf("fetching station info: %w", err)
+			}
+			deviceID = extractTempestDeviceID(station)
+			if deviceID == 0 {
+				return fmt.Errorf("no Tempest device found for station %d", sid)
+			}
+		}
+
+		d, err := client.GetDeviceObservations(ctx, deviceID, start, end)
+		if err != nil {
+			return fmt.Errorf("fetching observations: %w", err)
+		}
+		reportStale(info)
+
+		obs := make([]ObsData, 0, len(d.Obs))
+		for _, row := range d.Obs {
+			obs = append(obs, ParseObsArray(row))
+		}
+
+		if outputFormat != nil {
+			converted := make([]ObsData, len(obs))
+			for i, o := range obs {
+				converted[i] = o.In(displayUnits)
+			}
+			return writeResult(converted, historyTable(obs, displayUnits))
+		}
+		return writeHistoryTable(obs)
+	},
+}
+
+// historyRange resolves the --start/--end/--last flags into a time range.
+func historyRange(now time.Time) (time.Time, time.Time, error) {
+	if historyLast != "" && (historyStart != "" || historyEnd != "") {
+		return time.Time{}, time.Time{}, fmt.Errorf("--last cannot be combined with --start or --end")
+	}
+
+	if historyLast != "" {
+		d, err := parseLookback(historyLast)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		return now.Add(-d), now, nil
+	}
+
+	end := now
+	if historyEnd != "" {
+		var err error
+		end, err = parseTimeFlag(historyEnd)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid --end: %w", err)
+		}
+	}
+	// Without --start, show the day leading up to the end.
+	start := end.Add(-24 * time.Hour)
+	if historyStart != "" {
+		var err error
+		start, err = parseTimeFlag(historyStart)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid --start: %w", err)
+		}
+	}
+	if !end.After(start) {
+		return time.Time{}, time.Time{}, fmt.Errorf("--end must be after --start")
+	}
+	return start, end, nil
+}
+
+// parseLookback parses a duration that may also be given in days ("7d").
+func parseLookback(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.ParseFloat(days, 64)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid --last %q", s)
+		}
+		return time.Duration(n * float64(24*time.Hour)), nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid --last %q", s)
+	}
+	return d, nil
+}
+
+// parseTimeFlag parses an absolute time given on the command line.
+func parseTimeFlag(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{"2006-01-02T15:04", "2006-01-02 15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized time %q", s)
+}
+
+// historyColumns returns the table and CSV header, with each measured
+// column suffixed by the unit it is shown in.
+func historyColumns(u units.Prefs) []string {
+	return []string{
+		"time", "temp_" + string(u.Temp), "humidity", "pressure_" + string(u.Pressure),
+		"wind_avg_" + string(u.Wind), "wind_gust_" + string(u.Wind), "wind_dir",
+		"rain_" + string(u.Precip), "uv", "solar_wm2", "strikes",
+	}
+}
+
+func historyRow(o ObsData, u units.Prefs) []string {
+	f := func(v float64, prec int) string { return strconv.FormatFloat(v, 'f', prec, 64) }
+	return []string{
+		o.Timestamp.Format(time.RFC3339),
+		f(units.Temperature(o.Temperature).In(u.Temp), 1),
+		f(o.Humidity, 0),
+		f(units.Pressure(o.Pressure).In(u.Pressure), u.Pressure.Decimals()),
+		f(units.Speed(o.WindAvg).In(u.Wind), u.Wind.Decimals()),
+		f(units.Speed(o.WindGust).In(u.Wind), u.Wind.Decimals()),
+		strconv.Itoa(o.WindDirection),
+		f(units.Precip(o.PrecipAccum).In(u.Precip), u.Precip.Decimals()+1),
+		f(o.UV, 1),
+		f(o.SolarRadiation, 0),
+		strconv.Itoa(o.LightningCount),
+	}
+}
+
+// historyTable is the tabular view of obs in the display units, used for
+// the default table as well as csv, tsv and template output.
+func historyTable(obs []ObsData, u units.Prefs) output.Table {
+	t := output.Table{Columns: historyColumns(u)}
+	for _, o := range obs {
+		t.Rows = append(t.Rows, historyRow(o, u))
+	}
+	return t
+}
+
+func writeHistoryTable(obs []ObsData) error {
+	if len(obs) == 0 {
+		fmt.Println("No observations in the requested range.")
+		return nil
+	}
+	t := historyTable(obs, displayUnits)
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, strings.Join(t.Columns, "\t")+"\t")
+	for _, row := range t.Rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t")+"\t")
+	}
+	return tw.Flush()
+}
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+
+	historyCmd.Flags().IntVarP(&historyDeviceID, "device", "d", 0, "Tempest device ID (default: the station's Tempest device)")
+	historyCmd.Flags().StringVar(&historyStart, "start", "", "Start of the time range")
+	historyCmd.Flags().StringVar(&historyEnd, "end", "", "End of the time range (default: now)")
+	historyCmd.Flags().StringVar(&historyLast, "last", "", "Look back this far from now, e.g. 24h or 7d (default 24h)")
+}
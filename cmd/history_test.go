@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHistoryRange(t *testing.T) {
+	now := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+	at := func(s string) time.Time {
+		t.Helper()
+		v, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+
+	tests := []struct {
+		name             string
+		start, end, last string
+		wantStart        time.Time
+		wantEnd          time.Time
+		wantErr          string
+	}{
+		{name: "default", wantStart: now.Add(-24 * time.Hour), wantEnd: now},
+		{name: "start only", start: "2025-01-08T00:00:00Z", wantStart: at("2025-01-08T00:00:00Z"), wantEnd: now},
+		{
+			name: "start and end", start: "2025-01-01T00:00:00Z", end: "2025-01-03T00:00:00Z",
+			wantStart: at("2025-01-01T00:00:00Z"), wantEnd: at("2025-01-03T00:00:00Z"),
+		},
+		{name: "end only", end: "2025-01-02T06:00:00Z", wantStart: at("2025-01-01T06:00:00Z"), wantEnd: at("2025-01-02T06:00:00Z")},
+		{name: "last", last: "7d", wantStart: now.Add(-7 * 24 * time.Hour), wantEnd: now},
+		{name: "last and start", last: "24h", start: "2025-01-08T00:00:00Z", wantErr: "--last cannot be combined"},
+		{name: "last and end", last: "24h", end: "2025-01-08T00:00:00Z", wantErr: "--last cannot be combined"},
+		{name: "end before start", start: "2025-01-03T00:00:00Z", end: "2025-01-02T00:00:00Z", wantErr: "--end must be after --start"},
+		{name: "invalid end", end: "tomorrow", wantErr: "invalid --end"},
+		{name: "invalid last", last: "-1h", wantErr: "invalid --last"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			historyStart, historyEnd, historyLast = tt.start, tt.end, tt.last
+			t.Cleanup(func() { historyStart, historyEnd, historyLast = "", "", "" })
+
+			start, end, err := historyRange(now)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !start.Equal(tt.wantStart) || !end.Equal(tt.wantEnd) {
+				t.Errorf("got %v to %v, want %v to %v", start, end, tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+}
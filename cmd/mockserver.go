@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"tempest-cli/tempest/tempesttest"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	mockAddr     string
+	mockFixtures string
+	mockToken    string
+	mockSpeed    string
+	mockQuiet    bool
+)
+
+var mockServerCmd = &cobra.Command{
+	Use:   "mock-server",
+	Short: "Serve a local stand-in for the Tempest REST and WebSocket APIs",
+	Long: `Run a mock of the Tempest APIs for offline development. It serves the
+stations, station, station observation, device observation history and
+better_forecast endpoints, and a WebSocket endpoint that acknowledges
+listen_start and listen_rapid_start and streams modelled obs_st and
+rapid_wind messages.
+
+Responses come from the fixture files in --fixtures when present:
+` + mockFixtureHelp() + `
+Anything not given is generated for station ` + fmt.Sprint(tempesttest.StationID) + `, whose Tempest is device ` + fmt.Sprint(tempesttest.DeviceID) + `.
+Forecast values are converted to the units_* parameters requested.
+
+Point the other commands at the mock with --api-url, or the api.url config
+key; the WebSocket URL is derived from it.`,
+	Example: `  tempest-cli mock-server --fixtures .
+  tempest-cli --api-url http://127.0.0.1:8080/swd/rest --token test forecast -s 1000
+  tempest-cli --api-url http://127.0.0.1:8080/swd/rest --token test live -s 1000`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		speed, err := parseSpeed(mockSpeed)
+		if err != nil {
+			return err
+		}
+		var fx tempesttest.Fixtures
+		if mockFixtures != "" {
+			if fx, err = tempesttest.LoadFixtures(mockFixtures); err != nil {
+				return err
+			}
+		}
+
+		opts := []tempesttest.Option{
+			tempesttest.WithToken(mockToken),
+			tempesttest.WithIntervals(time.Duration(float64(time.Minute)/speed), time.Duration(float64(3*time.Second)/speed)),
+		}
+		if !mockQuiet {
+			logger := log.New(os.Stderr, "", log.Ltime)
+			opts = append(opts, tempesttest.WithLogger(logger.Printf))
+		}
+
+		ln, err := net.Listen("tcp", mockAddr)
+		if err != nil {
+			return err
+		}
+		srv := &http.Server{Handler: tempesttest.NewHandler(fx, opts...)}
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+		defer stop()
+		go func() {
+			<-ctx.Done()
+			shutdown, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+			srv.Shutdown(shutdown)
+		}()
+
+		addr := ln.Addr().String()
+		fmt.Printf("Mock Tempest API listening on %s (Ctrl-C to stop)\n", addr)
+		fmt.Printf("  REST:      http://%s%s\n", addr, tempesttest.RESTPath)
+		fmt.Printf("  WebSocket: ws://%s%s\n", addr, tempesttest.WSPath)
+		fmt.Printf("Use it with: tempest-cli --api-url http://%s%s ...\n", addr, tempesttest.RESTPath)
+
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	},
+}
+
+// mockFixtureHelp lists the fixture files the mock server reads.
+func mockFixtureHelp() string {
+	var s string
+	for _, name := range tempesttest.Files() {
+		s += "  " + name + "\n"
+	}
+	return s
+}
+
+func init() {
+	rootCmd.AddCommand(mockServerCmd)
+
+	mockServerCmd.Flags().StringVar(&mockAddr, "addr", "127.0.0.1:8080", "Address to listen on")
+	mockServerCmd.Flags().StringVar(&mockFixtures, "fixtures", "", "Directory of fixture files to serve (default: generated responses)")
+	mockServerCmd.Flags().StringVar(&mockToken, "require-token", "", "Only accept this API token (default: any non-empty token)")
+	mockServerCmd.Flags().StringVar(&mockSpeed, "speed", "1", "Speed-up factor of the WebSocket message intervals, e.g. 10x")
+	mockServerCmd.Flags().BoolVarP(&mockQuiet, "quiet", "q", false, "Do not log requests")
+}
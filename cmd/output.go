@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"os"
+
+	"tempest-cli/output"
+
+	"github.com/spf13/cobra"
+)
+
+// outputFormat is the formatter selected with --output, or nil when each
+// command should print its own human-readable view.
+var outputFormat output.Formatter
+
+// resolveOutput validates --output so an unknown format fails before any
+// API request is made.
+func resolveOutput(cmd *cobra.Command) error {
+	f := cmd.Flags().Lookup("output")
+	if f == nil || f.Value.String() == "" {
+		return nil
+	}
+	var err error
+	outputFormat, err = output.New(f.Value.String())
+	return err
+}
+
+// writeResult prints a command result in the --output format. value is the
+// full result, rows its tabular view for csv, tsv, table and template output
+// (nil to use value).
+func writeResult(value, rows any) error {
+	return outputFormat.Format(os.Stdout, output.Result{Value: value, Rows: rows})
+}
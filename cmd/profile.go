@@ -0,0 +1,287 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"tempest-cli/config"
+	"tempest-cli/tempest"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	profileFromStation string
+	profileUnits       = map[string]*string{
+		"units.temp":     new(string),
+		"units.wind":     new(string),
+		"units.pressure": new(string),
+		"units.precip":   new(string),
+		"units.distance": new(string),
+	}
+)
+
+var profileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: "Manage named station profiles",
+	Long: `Profiles give stations a memorable alias that can be used anywhere a
+station ID is accepted, e.g. "tempest-cli forecast -s roof". A profile may
+also carry its own API token, for stations that belong to another account,
+and its own unit preferences.
+
+A group names several stations, for the live dashboard to watch at once,
+e.g. "tempest-cli live -s north".`,
+}
+
+var profileAddCmd = &cobra.Command{
+	Use:   "add <alias> [station_id]",
+	Short: "Add or update a station profile",
+	Long: `Add or update a station profile.
+
+The station can be given as an ID, or looked up by name from the station
+listing with --from-station. Without either, the account's only station is
+used. A token given with --token is stored in the profile and used whenever
+the alias is selected.`,
+	Example: `  tempest-cli profile add roof 12345
+  tempest-cli profile add field1 --from-station "North Field" --token <token>
+  tempest-cli profile add home 67890 --temp f --wind mph`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		alias := args[0]
+		if !config.ValidAlias(alias) {
+			return fmt.Errorf("invalid alias %q: use letters, digits, '-' and '_', and at least one letter", alias)
+		}
+
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+		if _, ok := cfg.Group(alias); ok {
+			return fmt.Errorf("%q is already a station group", alias)
+		}
+
+		var stationID string
+		switch {
+		case len(args) == 2:
+			id, err := tempest.ParseStationID(args[1])
+			if err != nil {
+				return err
+			}
+			stationID = strconv.Itoa(id)
+		default:
+			token, err := getAPIToken()
+			if err != nil {
+				return err
+			}
+			id, name, err := findStation(cmd, token, profileFromStation)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Using station %d (%s)\n", id, name)
+			stationID = strconv.Itoa(id)
+		}
+
+		cfg.Set(config.ProfileKey(alias, "station"), stationID)
+		// Only a token given explicitly belongs to the profile; one picked up
+		// from the environment or config is the account default.
+		if cmd.Flags().Changed("token") {
+			cfg.Set(config.ProfileKey(alias, "token"), apiToken)
+		}
+		for key, v := range profileUnits {
+			if *v == "" {
+				continue
+			}
+			if err := validateUnitSetting(key, *v); err != nil {
+				return err
+			}
+			cfg.Set(config.ProfileKey(alias, key), *v)
+		}
+		if err := cfg.Save(); err != nil {
+			return fmt.Errorf("saving config: %w", err)
+		}
+		fmt.Printf("Saved profile %q for station %s\n", alias, stationID)
+		return nil
+	},
+}
+
+// findStation picks a station from the account listing, by case-insensitive
+// name match when name is given, or the only station otherwise.
+func findStation(cmd *cobra.Command, token, name string) (int, string, error) {
+	stations, err := newAPIClientWithToken(token).ListStations(cmd.Context())
+	if err != nil {
+		return 0, "", fmt.Errorf("fetching stations: %w", err)
+	}
+
+	type candidate struct {
+		id   int
+		name string
+	}
+	var matches []candidate
+	for _, s := range stations.Stations {
+		if name == "" ||
+			strings.Contains(strings.ToLower(s.Name), strings.ToLower(name)) ||
+			strings.Contains(strings.ToLower(s.PublicName), strings.ToLower(name)) {
+			matches = append(matches, candidate{s.StationID, s.Name})
+		}
+	}
+
+	if len(matches) == 1 {
+		return matches[0].id, matches[0].name, nil
+	}
+	var names []string
+	for _, m := range matches {
+		names = append(names, fmt.Sprintf("%d (%s)", m.id, m.name))
+	}
+	switch {
+	case len(matches) == 0 && name != "":
+		return 0, "", fmt.Errorf("no station matches %q", name)
+	case len(matches) == 0:
+		return 0, "", fmt.Errorf("no stations found for this token")
+	default:
+		return 0, "", fmt.Errorf("several stations match, pass a station ID or a more specific --from-station: %s",
+			strings.Join(names, ", "))
+	}
+}
+
+var profileGroupCmd = &cobra.Command{
+	Use:   "group <name> <station>...",
+	Short: "Add or update a group of stations",
+	Long: `Add or update a named group of stations, given as station IDs or profile
+aliases. Pass the group name to -s of the live dashboard to watch every
+station in it.`,
+	Example: `  tempest-cli profile group north roof field1 67890
+  tempest-cli live -s north`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		if !config.ValidAlias(name) {
+			return fmt.Errorf("invalid group name %q: use letters, digits, '-' and '_', and at least one letter", name)
+		}
+
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+		if cfg.HasProfile(name) {
+			return fmt.Errorf("%q is already a station profile", name)
+		}
+		for _, s := range args[1:] {
+			if _, ok := cfg.Group(s); ok {
+				return fmt.Errorf("groups cannot contain other groups: %q", s)
+			}
+			if _, _, err := resolveStation(cfg, s); err != nil {
+				return err
+			}
+		}
+
+		cfg.Set(config.GroupKey(name), strings.Join(args[1:], ","))
+		if err := cfg.Save(); err != nil {
+			return fmt.Errorf("saving config: %w", err)
+		}
+		fmt.Printf("Saved group %q with %d stations\n", name, len(args)-1)
+		return nil
+	},
+}
+
+var profileRemoveCmd = &cobra.Command{
+	Use:     "remove <alias>",
+	Aliases: []string{"rm"},
+	Short:   "Remove a station profile or group",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+		_, isGroup := cfg.Group(args[0])
+		if !cfg.HasProfile(args[0]) && !isGroup {
+			return fmt.Errorf("no profile or group named %q", args[0])
+		}
+		cfg.RemoveProfile(args[0])
+		cfg.Unset(config.GroupKey(args[0]))
+		if err := cfg.Save(); err != nil {
+			return fmt.Errorf("saving config: %w", err)
+		}
+		return nil
+	},
+}
+
+// profileEntry is a row of `profile list`.
+type profileEntry struct {
+	Alias   string `json:"alias"`
+	Station string `json:"station"`
+	Token   string `json:"token"`
+	Units   string `json:"units"`
+}
+
+var profileListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List station profiles and groups",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+		aliases := cfg.Profiles()
+		groups := cfg.Groups()
+		if len(aliases) == 0 && len(groups) == 0 && outputFormat == nil {
+			fmt.Println("No profiles configured. Add one with `tempest-cli profile add <alias> <station_id>`.")
+			return nil
+		}
+
+		entries := []profileEntry{}
+		for _, alias := range aliases {
+			get := func(key string) string {
+				v, _ := cfg.Get(config.ProfileKey(alias, key))
+				return v
+			}
+			var units []string
+			for _, key := range []string{"units.temp", "units.wind", "units.pressure", "units.precip", "units.distance"} {
+				if v := get(key); v != "" {
+					units = append(units, strings.TrimPrefix(key, "units.")+"="+v)
+				}
+			}
+			var token string
+			if t := get("token"); t != "" {
+				token = displayConfigValue("token", t)
+			}
+			entries = append(entries, profileEntry{alias, get("station"), token, strings.Join(units, " ")})
+		}
+		for _, name := range groups {
+			members, _ := cfg.Group(name)
+			entries = append(entries, profileEntry{Alias: name, Station: strings.Join(members, ",")})
+		}
+		if outputFormat != nil {
+			return writeResult(entries, nil)
+		}
+
+		dash := func(s string) string {
+			if s == "" {
+				return "-"
+			}
+			return s
+		}
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "ALIAS\tSTATION\tTOKEN\tUNITS")
+		for _, e := range entries {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", e.Alias, e.Station, dash(e.Token), dash(e.Units))
+		}
+		return tw.Flush()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(profileCmd)
+	profileCmd.AddCommand(profileAddCmd, profileGroupCmd, profileRemoveCmd, profileListCmd)
+
+	profileAddCmd.Flags().StringVar(&profileFromStation, "from-station", "", "Pick the station from the account's station listing by name")
+	profileAddCmd.Flags().StringVar(profileUnits["units.temp"], "temp", "", "Temperature unit for this station: c, f or k")
+	profileAddCmd.Flags().StringVar(profileUnits["units.wind"], "wind", "", "Wind speed unit for this station: mps, kph, mph, kts or bft")
+	profileAddCmd.Flags().StringVar(profileUnits["units.pressure"], "pressure", "", "Pressure unit for this station: mb, hpa, inhg or mmhg")
+	profileAddCmd.Flags().StringVar(profileUnits["units.precip"], "precip", "", "Precipitation unit for this station: mm, cm or in")
+	profileAddCmd.Flags().StringVar(profileUnits["units.distance"], "distance", "", "Distance unit for this station: km or mi")
+}
@@ -4,11 +4,26 @@ Copyright © 2025 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
+	"errors"
+	"fmt"
 	"os"
+	"strings"
+
+	"tempest-cli/output"
+	"tempest-cli/tempest"
 
 	"github.com/spf13/cobra"
 )
 
+// Exit codes returned by the CLI so scripts can tell failures apart.
+const (
+	exitError        = 1
+	exitUnauthorized = 3
+	exitNotFound     = 4
+	exitRateLimited  = 5
+	exitServerError  = 6
+)
+
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use:   "tempest-cli",
@@ -19,6 +34,23 @@ var rootCmd = &cobra.Command{
 	// Uncomment the following line if your bare application
 	// has an action associated with it:
 	// Run: func(cmd *cobra.Command, args []string) { },
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		err := applyConfig(cmd)
+		if err == nil {
+			err = resolveUnits(cmd)
+		}
+		if err == nil {
+			err = resolveTheme(cmd)
+		}
+		// The config and profile commands must keep working so a broken file
+		// can be fixed.
+		if err != nil && !isConfigCommand(cmd) {
+			return err
+		}
+		return resolveOutput(cmd)
+	},
+	SilenceUsage:  true,
+	SilenceErrors: true,
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
@@ -26,12 +58,48 @@ var rootCmd = &cobra.Command{
 func Execute() {
 	err := rootCmd.Execute()
 	if err != nil {
-		os.Exit(1)
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(exitCode(err))
+	}
+}
+
+// isConfigCommand reports whether cmd is the config or profile command or one
+// of their subcommands.
+func isConfigCommand(cmd *cobra.Command) bool {
+	for c := cmd; c != nil; c = c.Parent() {
+		if c == configCmd || c == profileCmd {
+			return true
+		}
+	}
+	return false
+}
+
+// exitCode maps an error to the process exit status.
+func exitCode(err error) int {
+	switch {
+	case errors.Is(err, tempest.ErrUnauthorized):
+		return exitUnauthorized
+	case errors.Is(err, tempest.ErrNotFound):
+		return exitNotFound
+	case errors.Is(err, tempest.ErrRateLimited):
+		return exitRateLimited
+	case errors.Is(err, tempest.ErrServer):
+		return exitServerError
 	}
+	return exitError
 }
 
 func init() {
 
-	rootCmd.PersistentFlags().StringP("station", "s", "", "Station ID to pull data from")
-	rootCmd.PersistentFlags().StringP("output", "o", "", "Output format - JSON")
+	rootCmd.PersistentFlags().StringP("station", "s", "", "Station ID to pull data from (default: TEMPEST_STATION or the configured station)")
+	rootCmd.PersistentFlags().StringVar(&apiToken, "token", "", "Tempest API token (default: TEMPEST_TOKEN, the config file or .env)")
+	rootCmd.PersistentFlags().StringVar(&configPath, "config", "", "Config file (default: $XDG_CONFIG_HOME/tempest-cli/config.toml)")
+	rootCmd.PersistentFlags().StringP("output", "o", "", "Output format: "+strings.Join(output.Names(), ", ")+", e.g. -o csv or -o template='{{.AirTemperature}}'")
+	rootCmd.PersistentFlags().IntVar(&apiRetries, "retries", tempest.DefaultRetryPolicy.MaxRetries, "Number of times to retry a failed API request")
+	rootCmd.PersistentFlags().IntVar(&apiRateLimit, "rate-limit", 0, "Maximum API requests per minute, 0 for no limit")
+	rootCmd.PersistentFlags().StringVar(&apiURL, "api-url", "", "Root of the REST API, e.g. a local mock-server (default: TEMPEST_API_URL or "+tempest.DefaultBaseURL+")")
+	rootCmd.PersistentFlags().StringVar(&wsURL, "ws-url", "", "WebSocket API endpoint (default: TEMPEST_WS_URL, derived from --api-url, or "+tempest.DefaultWSURL+")")
+	rootCmd.PersistentFlags().BoolVar(&noCache, "no-cache", false, "Do not read or write the on-disk response cache")
+	rootCmd.PersistentFlags().DurationVar(&cacheMaxAge, "max-age", 0, "Maximum age of cached responses to use, e.g. 30s or 5m (overrides per-endpoint defaults)")
+	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Print diagnostic output, such as retried requests, to stderr")
 }
@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// sessionLine is one line of a recorded session: a raw inbound message and
+// when it was received.
+type sessionLine struct {
+	Received time.Time       `json:"received"`
+	Message  json.RawMessage `json:"message"`
+}
+
+// sessionRecorder writes every message it sees to a session file, one JSON
+// object per line. It is safe for concurrent use.
+type sessionRecorder struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+	err error
+}
+
+func newSessionRecorder(w io.Writer) *sessionRecorder {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return &sessionRecorder{enc: enc}
+}
+
+// record appends raw to the session. Messages that are not JSON are
+// skipped. After the first write error nothing more is written; Err
+// reports it.
+func (r *sessionRecorder) record(raw []byte) {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, raw); err != nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err == nil {
+		r.err = r.enc.Encode(sessionLine{Received: time.Now(), Message: buf.Bytes()})
+	}
+}
+
+// Err returns the error that stopped the recording, if any.
+func (r *sessionRecorder) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+// wrap returns a decoder that records each message before decoding it.
+func (r *sessionRecorder) wrap(decode func([]byte) tea.Msg) func([]byte) tea.Msg {
+	return func(raw []byte) tea.Msg {
+		r.record(raw)
+		return decode(raw)
+	}
+}
+
+// parseReplaySpeed parses --speed for a replay. Besides a speed-up factor
+// it accepts 0, to replay without pausing.
+func parseReplaySpeed(s string) (float64, error) {
+	if v, err := strconv.ParseFloat(strings.TrimSuffix(strings.ToLower(strings.TrimSpace(s)), "x"), 64); err == nil && v == 0 {
+		return 0, nil
+	}
+	speed, err := parseSpeed(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid speed %q, use a positive factor such as 10 or 10x, or 0 for no pauses", s)
+	}
+	return speed, nil
+}
+
+// replaySession delivers the messages of a recorded session to msgCh,
+// decoded with decode, until the recording ends or ctx is cancelled. The
+// pauses between messages are the recorded ones divided by speed; a speed
+// of 0 replays without pausing, as fast as msgCh is drained.
+func replaySession(ctx context.Context, r io.Reader, speed float64, msgCh chan<- tea.Msg, decode func([]byte) tea.Msg) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var prev time.Time
+	for n := 1; sc.Scan(); n++ {
+		if len(bytes.TrimSpace(sc.Bytes())) == 0 {
+			continue
+		}
+		var line sessionLine
+		if err := json.Unmarshal(sc.Bytes(), &line); err != nil {
+			return fmt.Errorf("session line %d: %w", n, err)
+		}
+
+		if speed > 0 && !prev.IsZero() && line.Received.After(prev) {
+			wait := time.Duration(float64(line.Received.Sub(prev)) / speed)
+			t := time.NewTimer(wait)
+			select {
+			case <-ctx.Done():
+				t.Stop()
+				return ctx.Err()
+			case <-t.C:
+			}
+		}
+		prev = line.Received
+
+		if msg := decode(line.Message); msg != nil && !deliver(msgCh, ctx.Done(), msg) {
+			return ctx.Err()
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return fmt.Errorf("reading session: %w", err)
+	}
+	return nil
+}
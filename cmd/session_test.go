@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"tempest-cli/units"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestSessionRoundTrip(t *testing.T) {
+	packets := []string{
+		udpRapidWind,
+		"not json",
+		udpObsSt,
+		`{"type":"ack","id":"1"}`,
+		udpStrike,
+		udpDeviceStatus,
+	}
+
+	var file bytes.Buffer
+	rec := newSessionRecorder(&file)
+	decode := rec.wrap(decodeMessage)
+	var want []tea.Msg
+	for _, p := range packets {
+		if msg := decode([]byte(p)); msg != nil {
+			want = append(want, msg)
+		}
+	}
+	if err := rec.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(file.String(), "\n"); lines != len(packets)-1 {
+		t.Errorf("recorded %d lines, want %d (every message but the one that is not JSON)", lines, len(packets)-1)
+	}
+
+	msgCh := make(chan tea.Msg, len(packets))
+	if err := replaySession(context.Background(), &file, 0, msgCh, decodeMessage); err != nil {
+		t.Fatal(err)
+	}
+	close(msgCh)
+	var got []tea.Msg
+	for msg := range msgCh {
+		got = append(got, msg)
+	}
+
+	if len(got) != 4 {
+		t.Fatalf("replayed %d messages, want 4", len(got))
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("replayed\n%#v\nwant\n%#v", got, want)
+	}
+}
+
+func TestReplaySessionPacing(t *testing.T) {
+	// Two messages recorded an hour apart.
+	session := `{"received":"2025-01-06T14:00:00Z","message":` + udpRapidWind + "}\n" +
+		"\n" +
+		`{"received":"2025-01-06T15:00:00Z","message":` + udpStrike + "}\n"
+
+	tests := []struct {
+		name  string
+		speed float64
+		min   time.Duration
+		max   time.Duration
+	}{
+		{"no pauses", 0, 0, time.Second},
+		{"sped up", 36000, 100 * time.Millisecond, 2 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msgCh := make(chan tea.Msg, 2)
+			start := time.Now()
+			if err := replaySession(context.Background(), strings.NewReader(session), tt.speed, msgCh, decodeMessage); err != nil {
+				t.Fatal(err)
+			}
+			if d := time.Since(start); d < tt.min || d > tt.max {
+				t.Errorf("replay took %v, want between %v and %v", d, tt.min, tt.max)
+			}
+			if len(msgCh) != 2 {
+				t.Errorf("replayed %d messages, want 2", len(msgCh))
+			}
+		})
+	}
+}
+
+func TestReplaySessionErrors(t *testing.T) {
+	msgCh := make(chan tea.Msg, 1)
+	err := replaySession(context.Background(), strings.NewReader("{\"received\":\"x\"}\n"), 0, msgCh, decodeMessage)
+	if err == nil || !strings.Contains(err.Error(), "session line 1") {
+		t.Errorf("got %v, want an error naming line 1", err)
+	}
+
+	// A replay stops when its context is cancelled while waiting.
+	session := `{"received":"2025-01-06T14:00:00Z","message":` + udpRapidWind + "}\n" +
+		`{"received":"2025-01-06T15:00:00Z","message":` + udpStrike + "}\n"
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := replaySession(ctx, strings.NewReader(session), 1, msgCh, decodeMessage); err != context.DeadlineExceeded {
+		t.Errorf("got %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestParseReplaySpeed(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    float64
+		wantErr bool
+	}{
+		{"0", 0, false},
+		{"0x", 0, false},
+		{"1", 1, false},
+		{"10x", 10, false},
+		{" 2.5X ", 2.5, false},
+		{"-1", 0, true},
+		{"fast", 0, true},
+		{"inf", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := parseReplaySpeed(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseReplaySpeed(%q) = %v, %v; want %v, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestStreamReplay(t *testing.T) {
+	session := `{"received":"2025-01-06T14:00:00Z","message":` + udpRapidWind + "}\n" +
+		`{"received":"2025-01-06T15:00:00Z","message":` + udpStrike + "}\n"
+
+	msgCh := make(chan tea.Msg, 1)
+	stop := make(chan struct{})
+	go func() {
+		err := replaySession(context.Background(), strings.NewReader(session), 0, msgCh, decodeRawMessage)
+		deliver(msgCh, stop, replayDoneMsg{err: err})
+	}()
+
+	var out bytes.Buffer
+	done := make(chan error)
+	go func() { done <- writeStream(&out, msgCh, stop, nil, units.Metric()) }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(2 * time.Second):
+		close(stop)
+		t.Fatal("stream did not end with the replay")
+	}
+
+	if want := udpRapidWind + "\n" + udpStrike + "\n"; out.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", out.String(), want)
+	}
+}
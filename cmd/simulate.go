@@ -0,0 +1,452 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"net"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"tempest-cli/units"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	simAddr     string
+	simScenario string
+	simSpeed    string
+	simDuration time.Duration
+	simSerial   string
+	simSeed     int64
+	simQuiet    bool
+)
+
+var simulateCmd = &cobra.Command{
+	Use:   "simulate",
+	Short: "Broadcast synthetic Tempest hub UDP packets",
+	Long: `Send realistic Tempest hub broadcasts (obs_st every minute, rapid_wind every
+3 seconds, evt_strike, evt_precip, device_status and hub_status) to a UDP
+address, following a scripted weather scenario. Point the live dashboard at
+it with "tempest-cli live --source udp".
+
+Scenarios:
+` + simScenarioHelp() + `
+--speed runs the simulated clock faster than real time, e.g. 10 or 10x. Packet
+timestamps follow the simulated clock.`,
+	Example: `  tempest-cli simulate --scenario thunderstorm --speed 20x
+  tempest-cli simulate --scenario sensor-failure --addr 255.255.255.255:50222`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sc, ok := simScenarios[strings.ToLower(simScenario)]
+		if !ok {
+			return fmt.Errorf("unknown scenario %q, valid scenarios are: %s", simScenario, strings.Join(simScenarioNames(), ", "))
+		}
+		speed, err := parseSpeed(simSpeed)
+		if err != nil {
+			return err
+		}
+		length := sc.length()
+		if simDuration > 0 {
+			length = simDuration
+		}
+
+		// An unconnected socket keeps sending when nobody is listening yet,
+		// where a connected one would fail with "connection refused".
+		dst, err := net.ResolveUDPAddr("udp4", simAddr)
+		if err != nil {
+			return fmt.Errorf("invalid --addr: %w", err)
+		}
+		conn, err := net.ListenPacket("udp4", ":0")
+		if err != nil {
+			return fmt.Errorf("udp socket: %w", err)
+		}
+		defer conn.Close()
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+		defer stop()
+
+		seed := simSeed
+		if seed == 0 {
+			seed = time.Now().UnixNano()
+		}
+		sim := &simulator{
+			scenario: sc,
+			conn:     conn,
+			dst:      dst,
+			rng:      rand.New(rand.NewPCG(uint64(seed), 0)),
+			serial:   simSerial,
+			hub:      "HB-" + strings.TrimPrefix(simSerial, "ST-"),
+			start:    time.Now(),
+			quiet:    simQuiet,
+		}
+		fmt.Printf("Simulating %s for %s at %gx to %s\n", sc.name, length, speed, simAddr)
+		if err := sim.run(ctx, length, speed); err != nil && ctx.Err() == nil {
+			return err
+		}
+		return nil
+	},
+}
+
+// parseSpeed parses a speed-up factor such as "10" or "10x".
+func parseSpeed(s string) (float64, error) {
+	v, err := strconv.ParseFloat(strings.TrimSuffix(strings.ToLower(strings.TrimSpace(s)), "x"), 64)
+	if err != nil || v <= 0 || math.IsInf(v, 0) {
+		return 0, fmt.Errorf("invalid speed %q, use a positive factor such as 10 or 10x", s)
+	}
+	return v, nil
+}
+
+// simFrame is the weather at a point in a scenario. Values between frames
+// are interpolated linearly; sensorStatus holds until the next frame.
+type simFrame struct {
+	at           time.Duration
+	temp         float64 // °C
+	humidity     float64 // %
+	pressure     float64 // mb
+	wind         float64 // m/s
+	windDir      float64 // degrees
+	lux          float64
+	rainRate     float64 // mm/h
+	strikeRate   float64 // strikes per minute
+	strikeDist   float64 // km
+	battery      float64 // V
+	sensorStatus int
+}
+
+type simScenarioDef struct {
+	name        string
+	description string
+	frames      []simFrame
+}
+
+func (s simScenarioDef) length() time.Duration {
+	return s.frames[len(s.frames)-1].at
+}
+
+// at returns the interpolated weather at offset d.
+func (s simScenarioDef) at(d time.Duration) simFrame {
+	frames := s.frames
+	if d <= frames[0].at {
+		return frames[0]
+	}
+	for i := 1; i < len(frames); i++ {
+		a, b := frames[i-1], frames[i]
+		if d > b.at {
+			continue
+		}
+		t := float64(d-a.at) / float64(b.at-a.at)
+		lerp := func(x, y float64) float64 { return x + (y-x)*t }
+		// Interpolate wind direction the short way round.
+		dirDelta := math.Mod(b.windDir-a.windDir+540, 360) - 180
+		return simFrame{
+			at:           d,
+			temp:         lerp(a.temp, b.temp),
+			humidity:     lerp(a.humidity, b.humidity),
+			pressure:     lerp(a.pressure, b.pressure),
+			wind:         lerp(a.wind, b.wind),
+			windDir:      math.Mod(a.windDir+dirDelta*t+360, 360),
+			lux:          lerp(a.lux, b.lux),
+			rainRate:     lerp(a.rainRate, b.rainRate),
+			strikeRate:   lerp(a.strikeRate, b.strikeRate),
+			strikeDist:   lerp(a.strikeDist, b.strikeDist),
+			battery:      lerp(a.battery, b.battery),
+			sensorStatus: a.sensorStatus,
+		}
+	}
+	return frames[len(frames)-1]
+}
+
+var simScenarios = map[string]simScenarioDef{
+	"thunderstorm": {
+		name:        "thunderstorm",
+		description: "a storm approaches from 35 km, passes overhead with heavy rain and hail, then moves away",
+		frames: []simFrame{
+			{at: 0, temp: 31, humidity: 55, pressure: 1012, wind: 2, windDir: 200, lux: 80000, strikeRate: 0.5, strikeDist: 35, battery: 2.62},
+			{at: 20 * time.Minute, temp: 30, humidity: 60, pressure: 1010, wind: 4, windDir: 220, lux: 40000, strikeRate: 3, strikeDist: 15, battery: 2.61},
+			{at: 30 * time.Minute, temp: 24, humidity: 85, pressure: 1008, wind: 12, windDir: 270, lux: 5000, rainRate: 25, strikeRate: 8, strikeDist: 4, battery: 2.60},
+			{at: 38 * time.Minute, temp: 20, humidity: 95, pressure: 1011, wind: 9, windDir: 290, lux: 3000, rainRate: 60, strikeRate: 10, strikeDist: 1, battery: 2.59},
+			{at: 50 * time.Minute, temp: 21, humidity: 92, pressure: 1012, wind: 5, windDir: 300, lux: 10000, rainRate: 8, strikeRate: 3, strikeDist: 12, battery: 2.59},
+			{at: 70 * time.Minute, temp: 23, humidity: 80, pressure: 1013, wind: 3, windDir: 300, lux: 30000, strikeRate: 0.3, strikeDist: 30, battery: 2.60},
+		},
+	},
+	"cold-front": {
+		name:        "cold-front",
+		description: "warm southerly air gives way to a gusty northwesterly with a short shower and a 12 °C drop",
+		frames: []simFrame{
+			{at: 0, temp: 24, humidity: 65, pressure: 1009, wind: 4, windDir: 180, lux: 50000, battery: 2.62},
+			{at: 40 * time.Minute, temp: 23, humidity: 75, pressure: 1004, wind: 6, windDir: 200, lux: 25000, battery: 2.62},
+			{at: 55 * time.Minute, temp: 17, humidity: 90, pressure: 1006, wind: 11, windDir: 290, lux: 8000, rainRate: 6, battery: 2.61},
+			{at: 70 * time.Minute, temp: 14, humidity: 70, pressure: 1011, wind: 9, windDir: 315, lux: 20000, battery: 2.61},
+			{at: 120 * time.Minute, temp: 12, humidity: 55, pressure: 1016, wind: 7, windDir: 320, lux: 35000, battery: 2.61},
+		},
+	},
+	"calm-night": {
+		name:        "calm-night",
+		description: "a clear, still night with the temperature falling towards the dew point",
+		frames: []simFrame{
+			{at: 0, temp: 12, humidity: 70, pressure: 1021, wind: 0.6, windDir: 40, battery: 2.55},
+			{at: 3 * time.Hour, temp: 8, humidity: 88, pressure: 1022, wind: 0.2, windDir: 60, battery: 2.52},
+			{at: 6 * time.Hour, temp: 6, humidity: 96, pressure: 1022, wind: 0, windDir: 60, battery: 2.50},
+		},
+	},
+	"sensor-failure": {
+		name:        "sensor-failure",
+		description: "the wind sensor fails, then temperature and humidity, before everything recovers and the battery sags",
+		frames: []simFrame{
+			{at: 0, temp: 18, humidity: 60, pressure: 1015, wind: 3, windDir: 90, lux: 30000, battery: 2.60},
+			{at: 10 * time.Minute, temp: 18, humidity: 60, pressure: 1015, wind: 3, windDir: 90, lux: 30000, battery: 2.50, sensorStatus: 0x040},
+			{at: 20 * time.Minute, temp: 19, humidity: 58, pressure: 1015, wind: 3, windDir: 100, lux: 32000, battery: 2.40, sensorStatus: 0x070},
+			{at: 35 * time.Minute, temp: 19, humidity: 58, pressure: 1014, wind: 3, windDir: 100, lux: 32000, battery: 2.35},
+			{at: 45 * time.Minute, temp: 19, humidity: 57, pressure: 1014, wind: 3, windDir: 110, lux: 31000, battery: 2.38},
+		},
+	},
+}
+
+func simScenarioNames() []string {
+	names := make([]string, 0, len(simScenarios))
+	for name := range simScenarios {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func simScenarioHelp() string {
+	var b strings.Builder
+	for _, name := range simScenarioNames() {
+		sc := simScenarios[name]
+		fmt.Fprintf(&b, "  %-15s %s (%s)\n", name, sc.description, sc.length())
+	}
+	return b.String()
+}
+
+// Packets as broadcast by the hub. Unlike the WebSocket API they carry
+// serial numbers instead of device IDs.
+type udpObsPacket struct {
+	SerialNumber     string      `json:"serial_number"`
+	Type             string      `json:"type"`
+	HubSN            string      `json:"hub_sn"`
+	Obs              [][]float64 `json:"obs"`
+	FirmwareRevision int         `json:"firmware_revision"`
+}
+
+type udpRapidWindPacket struct {
+	SerialNumber string    `json:"serial_number"`
+	Type         string    `json:"type"`
+	HubSN        string    `json:"hub_sn"`
+	Ob           []float64 `json:"ob"`
+}
+
+type udpEventPacket struct {
+	SerialNumber string    `json:"serial_number"`
+	Type         string    `json:"type"`
+	HubSN        string    `json:"hub_sn"`
+	Evt          []float64 `json:"evt"`
+}
+
+// simulator emits the packets of one scenario run.
+type simulator struct {
+	scenario simScenarioDef
+	conn     net.PacketConn
+	dst      net.Addr
+	rng      *rand.Rand
+	serial   string
+	hub      string
+	start    time.Time
+	quiet    bool
+
+	raining    bool
+	rainAccum  float64   // mm since the last obs_st
+	strikes    []float64 // distances since the last obs_st
+	lastHubMsg time.Duration
+}
+
+const (
+	simStep        = 3 * time.Second
+	simObsInterval = time.Minute
+	simHubInterval = 10 * time.Second
+	simFirmware    = 172
+)
+
+// run steps the simulated clock through length, sleeping step/speed of real
+// time between steps.
+func (s *simulator) run(ctx context.Context, length time.Duration, speed float64) error {
+	wait := time.Duration(float64(simStep) / speed)
+	s.lastHubMsg = -simHubInterval
+	for elapsed := time.Duration(0); elapsed <= length; elapsed += simStep {
+		if err := s.step(elapsed); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+	fmt.Println("Scenario complete")
+	return nil
+}
+
+func (s *simulator) step(elapsed time.Duration) error {
+	f := s.scenario.at(elapsed)
+	now := s.start.Add(elapsed)
+	ts := float64(now.Unix())
+
+	windFailed := f.sensorStatus&0x040 != 0
+	gust := math.Max(0, f.wind*(1+0.5*s.rng.Float64())+0.3*s.rng.NormFloat64())
+	dir := math.Mod(f.windDir+15*s.rng.NormFloat64()+360, 360)
+	if windFailed {
+		gust, dir = 0, 0
+	}
+	if err := s.send(udpRapidWindPacket{s.serial, "rapid_wind", s.hub, []float64{ts, round(gust, 2), math.Round(dir)}}); err != nil {
+		return err
+	}
+
+	if f.rainRate > 0 && !s.raining {
+		if err := s.send(udpEventPacket{s.serial, "evt_precip", s.hub, []float64{ts}}); err != nil {
+			return err
+		}
+		s.logf(elapsed, "evt_precip rain started")
+	}
+	s.raining = f.rainRate > 0
+	s.rainAccum += f.rainRate * simStep.Hours()
+
+	// Strikes arrive as a Poisson process at strikeRate per minute.
+	if s.rng.Float64() < f.strikeRate*simStep.Minutes() && f.sensorStatus&0x001 == 0 {
+		dist := math.Max(1, math.Round(f.strikeDist+3*s.rng.NormFloat64()))
+		energy := math.Round(1000 + 20000*s.rng.Float64())
+		s.strikes = append(s.strikes, dist)
+		if err := s.send(udpEventPacket{s.serial, "evt_strike", s.hub, []float64{ts, dist, energy}}); err != nil {
+			return err
+		}
+		s.logf(elapsed, "evt_strike %s", displayUnits.FormatDistance(units.Distance(dist)))
+	}
+
+	if elapsed-s.lastHubMsg >= simHubInterval {
+		s.lastHubMsg = elapsed
+		if err := s.send(UDPHubStatus{
+			Type:             "hub_status",
+			SerialNumber:     s.hub,
+			FirmwareRevision: strconv.Itoa(simFirmware),
+			Uptime:           int(elapsed.Seconds()),
+			RSSI:             -50 - s.rng.IntN(10),
+			Timestamp:        now.Unix(),
+			ResetFlags:       "BOR,PIN,POR",
+			Seq:              int(elapsed / simHubInterval),
+		}); err != nil {
+			return err
+		}
+	}
+
+	if elapsed%simObsInterval != 0 {
+		return nil
+	}
+	if err := s.send(s.observation(f, ts, windFailed)); err != nil {
+		return err
+	}
+	return s.send(UDPDeviceStatus{
+		Type:             "device_status",
+		SerialNumber:     s.serial,
+		HubSN:            s.hub,
+		Timestamp:        now.Unix(),
+		Uptime:           int(elapsed.Seconds()),
+		Voltage:          round(f.battery, 2),
+		FirmwareRevision: simFirmware,
+		RSSI:             -60 - s.rng.IntN(15),
+		HubRSSI:          -55 - s.rng.IntN(15),
+		SensorStatus:     f.sensorStatus,
+	})
+}
+
+// observation builds the obs_st for the minute ending at ts and resets the
+// per-minute rain and lightning counters.
+func (s *simulator) observation(f simFrame, ts float64, windFailed bool) udpObsPacket {
+	noise := func(v, sd float64) float64 { return v + sd*s.rng.NormFloat64() }
+	lull := math.Max(0, noise(f.wind*0.6, 0.2))
+	avg := math.Max(lull, noise(f.wind, 0.2))
+	gust := math.Max(avg, noise(f.wind*1.5, 0.4))
+	if windFailed {
+		lull, avg, gust = 0, 0, 0
+	}
+	temp, humidity := noise(f.temp, 0.1), math.Min(100, math.Max(0, noise(f.humidity, 0.5)))
+	if f.sensorStatus&0x010 != 0 {
+		temp = 0
+	}
+	if f.sensorStatus&0x020 != 0 {
+		humidity = 0
+	}
+	precipType := 0.0
+	switch {
+	case f.rainRate > 40:
+		precipType = 3 // rain and hail
+	case f.rainRate > 0:
+		precipType = 1
+	}
+	strikeDist := 0.0
+	for _, d := range s.strikes {
+		strikeDist += d / float64(len(s.strikes))
+	}
+	lux := math.Max(0, noise(f.lux, f.lux*0.02))
+
+	obs := []float64{
+		ts,
+		round(lull, 2), round(avg, 2), round(gust, 2), math.Round(f.windDir),
+		simStep.Seconds(),
+		round(noise(f.pressure, 0.05), 2),
+		round(temp, 2), round(humidity, 2),
+		math.Round(lux), round(lux/12000, 2), math.Round(lux / 120),
+		round(s.rainAccum, 3), precipType,
+		math.Round(strikeDist), float64(len(s.strikes)),
+		round(f.battery, 2),
+		1,
+	}
+	s.logf(f.at, "obs_st %s %.0f%% %s %s rain %s strikes %d",
+		displayUnits.FormatTemp(units.Temperature(temp)), humidity,
+		displayUnits.FormatPressure(units.Pressure(f.pressure)),
+		displayUnits.FormatSpeed(units.Speed(avg)),
+		displayUnits.FormatPrecip(units.Precip(s.rainAccum)), len(s.strikes))
+	s.rainAccum, s.strikes = 0, nil
+	return udpObsPacket{s.serial, "obs_st", s.hub, [][]float64{obs}, simFirmware}
+}
+
+func (s *simulator) send(packet any) error {
+	data, err := json.Marshal(packet)
+	if err != nil {
+		return err
+	}
+	if _, err := s.conn.WriteTo(data, s.dst); err != nil {
+		return fmt.Errorf("udp send: %w", err)
+	}
+	return nil
+}
+
+func (s *simulator) logf(elapsed time.Duration, format string, args ...any) {
+	if s.quiet {
+		return
+	}
+	fmt.Printf("[+%s] "+format+"\n", append([]any{elapsed}, args...)...)
+}
+
+func round(v float64, decimals int) float64 {
+	p := math.Pow(10, float64(decimals))
+	return math.Round(v*p) / p
+}
+
+func init() {
+	rootCmd.AddCommand(simulateCmd)
+
+	simulateCmd.Flags().StringVar(&simAddr, "addr", "127.0.0.1:50222", "UDP address to send to, e.g. 255.255.255.255:50222 to broadcast")
+	simulateCmd.Flags().StringVar(&simScenario, "scenario", "thunderstorm", "Weather scenario: "+strings.Join(simScenarioNames(), ", "))
+	simulateCmd.Flags().StringVar(&simSpeed, "speed", "1", "Speed-up factor of the simulated clock, e.g. 10x")
+	simulateCmd.Flags().DurationVar(&simDuration, "duration", 0, "Simulated time to run for (default: the scenario's length)")
+	simulateCmd.Flags().StringVar(&simSerial, "serial", "ST-00000001", "Serial number of the simulated Tempest device")
+	simulateCmd.Flags().Int64Var(&simSeed, "seed", 0, "Random seed, for repeatable runs (default: time based)")
+	simulateCmd.Flags().BoolVarP(&simQuiet, "quiet", "q", false, "Do not log the packets sent")
+}
@@ -0,0 +1,281 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"tempest-cli/tempest"
+	"tempest-cli/units"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+)
+
+var (
+	streamSource   string
+	streamUDPAddr  string
+	streamDeviceID int
+	streamTypes    []string
+	streamRaw      bool
+	streamReplay   string
+	streamSpeed    string
+)
+
+// streamMessageTypes are the message types stream can emit.
+var streamMessageTypes = []string{
+	"device_status", "evt_device_offline", "evt_device_online", "evt_precip",
+	"evt_station_offline", "evt_station_online", "evt_strike", "hub_status",
+	"obs_st", "rapid_wind",
+}
+
+// eventMessageTypes maps EventData types to the message they came from.
+var eventMessageTypes = map[string]string{
+	EventRain:           "evt_precip",
+	EventLightning:      "evt_strike",
+	EventDeviceOnline:   "evt_device_online",
+	EventDeviceOffline:  "evt_device_offline",
+	EventStationOnline:  "evt_station_online",
+	EventStationOffline: "evt_station_offline",
+}
+
+var streamCmd = &cobra.Command{
+	Use:   "stream",
+	Short: "Write live observations and events to stdout as NDJSON",
+	Long: `Connect to the Tempest WebSocket API (or listen for local hub broadcasts with
+--source udp) and write one JSON object per message to stdout, for piping
+into jq, a log file or another program.
+
+Each object has the message type, the display units in effect and the parsed
+data converted to those units:
+
+  {"type":"obs_st","units":{"temp":"c",...},"data":{"temperature":21.4,...}}
+
+With --raw the messages are passed through as received instead, with their
+whitespace removed so each stays on one line.
+
+--replay reads a session saved with "websocket --record" instead of
+connecting, and ends with the recording. --speed 0 replays it without
+pausing, for scripts and tests.
+The stream ends cleanly on Ctrl-C or when the reading end of the pipe
+closes.`,
+	Example: `  tempest-cli stream -s 12345 | jq .data.temperature
+  tempest-cli stream -s 12345 --types evt_strike,evt_precip >> events.ndjson
+  tempest-cli stream --source udp --raw
+  tempest-cli stream --replay storm.ndjson --speed 0`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		types, err := parseStreamTypes(streamTypes)
+		if err != nil {
+			return err
+		}
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		// Report a closed stdout pipe as EPIPE from Write instead of letting
+		// SIGPIPE kill the process, so it can exit cleanly.
+		signal.Notify(make(chan os.Signal, 1), syscall.SIGPIPE)
+
+		decode := decodeMessage
+		if streamRaw {
+			decode = decodeRawMessage
+		}
+		msgCh := make(chan tea.Msg, 64)
+
+		switch {
+		case streamReplay != "":
+			for _, name := range []string{"source", "udp-addr", "device"} {
+				if cmd.Flags().Changed(name) {
+					return fmt.Errorf("--replay cannot be combined with --%s", name)
+				}
+			}
+			speed, err := parseReplaySpeed(streamSpeed)
+			if err != nil {
+				return err
+			}
+			f, err := os.Open(streamReplay)
+			if err != nil {
+				return fmt.Errorf("opening recording: %w", err)
+			}
+			defer f.Close()
+			go func() {
+				err := replaySession(ctx, f, speed, msgCh, decode)
+				deliver(msgCh, ctx.Done(), replayDoneMsg{err: err})
+			}()
+		case streamSource == "ws":
+			deviceID, token, err := streamDevice(cmd)
+			if err != nil {
+				return err
+			}
+			ws := newLiveWSClient(token, msgCh, ctx.Done(), deviceID)
+			go runWSClient(ctx, ws, msgCh, decode)
+		case streamSource == "udp":
+			conn, err := listenUDP(streamUDPAddr)
+			if err != nil {
+				return err
+			}
+			defer conn.Close()
+			go udpReadLoop(conn, msgCh, ctx.Done(), decode)
+		default:
+			return fmt.Errorf("invalid --source %q, valid sources are: ws, udp", streamSource)
+		}
+
+		err = writeStream(os.Stdout, msgCh, ctx.Done(), types, displayUnits)
+		if errors.Is(err, syscall.EPIPE) {
+			return nil
+		}
+		return err
+	},
+}
+
+// streamDevice finds the device to subscribe to, from --device or the
+// station's Tempest device.
+func streamDevice(cmd *cobra.Command) (int, string, error) {
+	token, err := getAPIToken()
+	if err != nil {
+		return 0, "", err
+	}
+	if streamDeviceID != 0 {
+		return streamDeviceID, token, nil
+	}
+	sid, err := stationIDFlag(cmd)
+	if err != nil {
+		return 0, "", fmt.Errorf("%w (or give a device ID with --device)", err)
+	}
+	station, err := fetchStationInfo(cmd.Context(), token, sid)
+	if err != nil {
+		return 0, "", fmt.Errorf("fetching station info: %w", err)
+	}
+	deviceID := extractTempestDeviceID(station)
+	if deviceID == 0 {
+		return 0, "", fmt.Errorf("no Tempest device found for station %d", sid)
+	}
+	return deviceID, token, nil
+}
+
+// parseStreamTypes validates --types. An empty result means every type.
+func parseStreamTypes(list []string) (map[string]bool, error) {
+	types := map[string]bool{}
+	for _, t := range list {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if t == "" {
+			continue
+		}
+		valid := false
+		for _, known := range streamMessageTypes {
+			valid = valid || t == known
+		}
+		if !valid {
+			return nil, fmt.Errorf("unknown message type %q, valid types are: %s", t, strings.Join(streamMessageTypes, ", "))
+		}
+		types[t] = true
+	}
+	return types, nil
+}
+
+// wsRawMsg is a message passed through undecoded in --raw mode.
+type wsRawMsg struct {
+	msgType string
+	raw     []byte
+}
+
+// decodeRawMessage wraps raw for passthrough, keeping only its type. The
+// bytes are copied since the UDP reader reuses its buffer.
+func decodeRawMessage(raw []byte) tea.Msg {
+	var envelope WSMessage
+	if err := json.Unmarshal(raw, &envelope); err != nil {
+		return nil
+	}
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, raw); err != nil {
+		return nil
+	}
+	return wsRawMsg{msgType: envelope.Type, raw: buf.Bytes()}
+}
+
+// streamRecord is one line of stream output.
+type streamRecord struct {
+	Type  string       `json:"type"`
+	Units *units.Prefs `json:"units,omitempty"`
+	Data  any          `json:"data"`
+}
+
+// newStreamRecord converts a decoded message to its output record, reporting
+// false for messages that are not streamed.
+func newStreamRecord(msg tea.Msg, u units.Prefs) (streamRecord, bool) {
+	switch msg := msg.(type) {
+	case wsObsMsg:
+		return streamRecord{"obs_st", &u, msg.obs.In(u)}, true
+	case wsRapidWindMsg:
+		return streamRecord{"rapid_wind", &u, msg.wind.In(u)}, true
+	case wsEventMsg:
+		t, ok := eventMessageTypes[msg.event.Type]
+		if !ok {
+			return streamRecord{}, false
+		}
+		return streamRecord{t, &u, msg.event.In(u)}, true
+	case wsStatusMsg:
+		return streamRecord{Type: msg.status.Kind + "_status", Data: msg.status}, true
+	}
+	return streamRecord{}, false
+}
+
+// writeStream writes messages from msgCh to w as NDJSON until stop is closed,
+// a read loop reports an error or a replay ends.
+func writeStream(w io.Writer, msgCh <-chan tea.Msg, stop <-chan struct{}, types map[string]bool, u units.Prefs) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	for {
+		var msg tea.Msg
+		select {
+		case <-stop:
+			return nil
+		case msg = <-msgCh:
+		}
+
+		var err error
+		switch msg := msg.(type) {
+		case wsErrorMsg:
+			return msg.err
+		case replayDoneMsg:
+			return msg.err
+		case wsStateMsg:
+			if c := msg.change; c.State == tempest.WSReconnecting {
+				fmt.Fprintf(os.Stderr, "[reconnecting] in %s: %v\n", c.Delay.Round(100*time.Millisecond), c.Err)
+			}
+			continue
+		case wsRawMsg:
+			if len(types) > 0 && !types[msg.msgType] {
+				continue
+			}
+			_, err = w.Write(append(msg.raw, '\n'))
+		default:
+			rec, ok := newStreamRecord(msg, u)
+			if !ok || len(types) > 0 && !types[rec.Type] {
+				continue
+			}
+			err = enc.Encode(rec)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(streamCmd)
+
+	streamCmd.Flags().StringVar(&streamSource, "source", "ws", "Data source: ws (Tempest WebSocket API) or udp (local hub broadcasts)")
+	streamCmd.Flags().StringVar(&streamUDPAddr, "udp-addr", DefaultUDPAddr, "Address to listen on for hub broadcasts with --source udp")
+	streamCmd.Flags().IntVarP(&streamDeviceID, "device", "d", 0, "Tempest device ID (default: the station's Tempest device)")
+	streamCmd.Flags().StringSliceVar(&streamTypes, "types", nil, "Only emit these message types: "+strings.Join(streamMessageTypes, ", "))
+	streamCmd.Flags().BoolVar(&streamRaw, "raw", false, "Pass messages through as received, compacted to one line each")
+	streamCmd.Flags().StringVar(&streamReplay, "replay", "", "Read a session saved with websocket --record instead of connecting")
+	streamCmd.Flags().StringVar(&streamSpeed, "speed", "1", "Playback speed-up for --replay, e.g. 10 or 10x, or 0 for no pauses")
+}
@@ -0,0 +1,282 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"tempest-cli/config"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"github.com/spf13/cobra"
+)
+
+var (
+	themeFlag string
+	colorFlag string
+)
+
+// Theme is the set of colors the styled output uses. Colors are "#RRGGBB",
+// an ANSI color number from 0 to 255, or "" for the terminal's own color;
+// whatever the terminal cannot show is mapped to the nearest color it can.
+type Theme struct {
+	// Text colors values and titles, Label labels and other secondary
+	// text, and Focus the border of the focused panel.
+	Text, Label, Focus string
+	// OK, Warning, Alert and Info color states: a live connection, a
+	// connection being made or a warning, an alert or a lost connection,
+	// and a replay.
+	OK, Warning, Alert, Info string
+	// Weather colors icons and borders by the icon of the conditions.
+	// Icons without colors of their own use those of their base icon, or
+	// else of "cloudy".
+	Weather map[string]WeatherTheme
+}
+
+// builtinThemes are the themes --theme accepts besides "auto" and the
+// user's own.
+var builtinThemes = map[string]Theme{
+	"dark": {
+		Text: "#FFFFFF", Label: "#888888", Focus: "#FFFFFF",
+		OK: "#00FF00", Warning: "#FFAA00", Alert: "#FF0000", Info: "#00AAFF",
+		Weather: weatherThemes,
+	},
+	"light": {
+		Text: "#1A1A1A", Label: "#6A6A6A", Focus: "#000000",
+		OK: "#1A8A1A", Warning: "#B86E00", Alert: "#C81E1E", Info: "#0066CC",
+		Weather: map[string]WeatherTheme{
+			"clear-day":           {Primary: "#B8860B", Secondary: "#B8860B"},
+			"clear-night":         {Primary: "#4A4A6A", Secondary: "#5A4ACD"},
+			"partly-cloudy-day":   {Primary: "#B8860B", Secondary: "#7A7A7A"},
+			"partly-cloudy-night": {Primary: "#4A4A6A", Secondary: "#7A7A7A"},
+			"cloudy":              {Primary: "#5A5A5A", Secondary: "#7A7A7A"},
+			"rainy":               {Primary: "#1F5FA8", Secondary: "#1F5FA8"},
+			"snow":                {Primary: "#3A6F9F", Secondary: "#3A6F9F"},
+			"sleet":               {Primary: "#2F6F8F", Secondary: "#2F6F8F"},
+			"thunderstorm":        {Primary: "#B8860B", Secondary: "#6A3FA0"},
+			"windy":               {Primary: "#2F6F8F", Secondary: "#2F6F8F"},
+			"foggy":               {Primary: "#6A6A6A", Secondary: "#7A7A7A"},
+		},
+	},
+	"high-contrast": {
+		Text: "#FFFFFF", Label: "#D7D7D7", Focus: "#FFFF00",
+		OK: "#00FF00", Warning: "#FFFF00", Alert: "#FF3030", Info: "#00FFFF",
+		Weather: map[string]WeatherTheme{
+			"clear-day":           {Primary: "#FFFF00", Secondary: "#FFFF00"},
+			"clear-night":         {Primary: "#FFFFFF", Secondary: "#FFFFFF"},
+			"partly-cloudy-day":   {Primary: "#FFFF00", Secondary: "#FFFFFF"},
+			"partly-cloudy-night": {Primary: "#FFFFFF", Secondary: "#FFFFFF"},
+			"cloudy":              {Primary: "#FFFFFF", Secondary: "#FFFFFF"},
+			"rainy":               {Primary: "#00BFFF", Secondary: "#00BFFF"},
+			"snow":                {Primary: "#FFFFFF", Secondary: "#00FFFF"},
+			"sleet":               {Primary: "#00FFFF", Secondary: "#00FFFF"},
+			"thunderstorm":        {Primary: "#FFFF00", Secondary: "#FF00FF"},
+			"windy":               {Primary: "#00FFFF", Secondary: "#00FFFF"},
+			"foggy":               {Primary: "#FFFFFF", Secondary: "#FFFFFF"},
+		},
+	},
+	// mono leaves every color to the terminal; the focused panel and the
+	// selected station still stand out by their thick border and reverse
+	// video.
+	"mono": {},
+}
+
+// themeNames returns the names --theme accepts: "auto", the built-in themes
+// and the themes in the config directory.
+func themeNames() []string {
+	names := []string{"auto", "dark", "light", "high-contrast", "mono"}
+	if dir, err := themeDir(); err == nil {
+		files, _ := filepath.Glob(filepath.Join(dir, "*"))
+		for _, f := range files {
+			ext := filepath.Ext(f)
+			if name := strings.TrimSuffix(filepath.Base(f), ext); (ext == ".toml" || ext == ".yaml" || ext == ".yml") && !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// weather returns the colors for the conditions icon.
+func (t Theme) weather(icon string) WeatherTheme {
+	w, ok := t.Weather[icon]
+	if !ok {
+		if base, isAlias := iconAliases[icon]; isAlias {
+			w, ok = t.Weather[base]
+		}
+	}
+	if !ok {
+		w = t.Weather["cloudy"]
+	}
+	w.Text, w.Label = t.Text, t.Label
+	return w
+}
+
+var (
+	// activeTheme is the theme chosen by resolveTheme; with "auto" it is
+	// picked by currentTheme on first use, since asking the terminal for
+	// its background takes a moment. The dashboard resolves it before
+	// starting, while the terminal is not yet in raw mode.
+	activeTheme     Theme
+	activeThemeAuto = true
+	themeOnce       sync.Once
+)
+
+// currentTheme returns the theme to render with.
+func currentTheme() Theme {
+	themeOnce.Do(func() {
+		if !activeThemeAuto {
+			return
+		}
+		activeTheme = builtinThemes["dark"]
+		if lipgloss.ColorProfile() != termenv.Ascii && !lipgloss.HasDarkBackground() {
+			activeTheme = builtinThemes["light"]
+		}
+	})
+	return activeTheme
+}
+
+// resolveTheme applies --color and loads the theme named by --theme.
+func resolveTheme(cmd *cobra.Command) error {
+	switch colorFlag {
+	case "auto":
+		// lipgloss follows the terminal and NO_COLOR by itself.
+	case "never":
+		lipgloss.SetColorProfile(termenv.Ascii)
+	case "always":
+		lipgloss.SetColorProfile(forcedColorProfile())
+	default:
+		return fmt.Errorf("invalid --color %q, valid values are: auto, always, never", colorFlag)
+	}
+
+	if themeFlag == "auto" {
+		activeThemeAuto = true
+		return nil
+	}
+	t, err := loadTheme(themeFlag)
+	if err != nil {
+		return err
+	}
+	activeTheme, activeThemeAuto = t, false
+	return nil
+}
+
+// forcedColorProfile returns the color profile for --color=always: the
+// terminal's, or when the output is not a terminal, the best COLORTERM and
+// TERM suggest.
+func forcedColorProfile() termenv.Profile {
+	if p := termenv.NewOutput(os.Stdout).ColorProfile(); p != termenv.Ascii {
+		return p
+	}
+	switch strings.ToLower(os.Getenv("COLORTERM")) {
+	case "truecolor", "24bit":
+		return termenv.TrueColor
+	}
+	if strings.Contains(os.Getenv("TERM"), "256color") {
+		return termenv.ANSI256
+	}
+	return termenv.ANSI
+}
+
+// themeDir is the directory user themes are read from.
+func themeDir() (string, error) {
+	dir, err := config.DefaultDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "themes"), nil
+}
+
+// loadTheme returns the built-in theme name, or the user theme in the file
+// name.toml or name.yaml of the theme directory.
+func loadTheme(name string) (Theme, error) {
+	if t, ok := builtinThemes[name]; ok {
+		return t, nil
+	}
+	dir, err := themeDir()
+	if err != nil {
+		return Theme{}, fmt.Errorf("locating theme directory: %w", err)
+	}
+	for _, ext := range []string{".toml", ".yaml", ".yml"} {
+		path := filepath.Join(dir, name+ext)
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		cfg, err := config.Load(path)
+		if err != nil {
+			return Theme{}, fmt.Errorf("loading theme: %w", err)
+		}
+		return parseTheme(cfg)
+	}
+	return Theme{}, fmt.Errorf("unknown theme %q, valid themes are: %s (user themes go in %s)",
+		name, strings.Join(themeNames(), ", "), dir)
+}
+
+// themeColorPattern matches the colors a theme file may give.
+var themeColorPattern = regexp.MustCompile(`^(#[0-9A-Fa-f]{6}|#[0-9A-Fa-f]{3}|[0-9]{1,3}|)$`)
+
+// parseTheme builds a user theme from its file. The theme starts as a copy
+// of its base, "dark" unless the file sets base, and the file overrides
+// any of text, label, focus, ok, warning, alert and info, and the primary
+// and secondary color of any icon under weather.<icon>.
+func parseTheme(cfg *config.Config) (Theme, error) {
+	path := cfg.Path()
+	baseName, _ := cfg.Get("base")
+	if baseName == "" {
+		baseName = "dark"
+	}
+	base, ok := builtinThemes[baseName]
+	if !ok {
+		return Theme{}, fmt.Errorf("%s: unknown base theme %q, valid bases are: dark, light, high-contrast, mono", path, baseName)
+	}
+	t := base
+	t.Weather = map[string]WeatherTheme{}
+	for icon, w := range base.Weather {
+		t.Weather[icon] = w
+	}
+
+	fields := map[string]*string{
+		"text": &t.Text, "label": &t.Label, "focus": &t.Focus,
+		"ok": &t.OK, "warning": &t.Warning, "alert": &t.Alert, "info": &t.Info,
+	}
+	for _, key := range cfg.Keys() {
+		if key == "base" {
+			continue
+		}
+		v, _ := cfg.Get(key)
+		if n, err := strconv.Atoi(v); !themeColorPattern.MatchString(v) || err == nil && n > 255 {
+			return Theme{}, fmt.Errorf("%s: %s: invalid color %q, use #RRGGBB or an ANSI color number", path, key, v)
+		}
+		if f, ok := fields[key]; ok {
+			*f = v
+			continue
+		}
+		rest, isWeather := strings.CutPrefix(key, "weather.")
+		icon, part, _ := strings.Cut(rest, ".")
+		if _, known := weatherIcons[icon]; !isWeather || !known && iconAliases[icon] == "" {
+			return Theme{}, fmt.Errorf("%s: unknown theme setting %q", path, key)
+		}
+		w := t.weather(icon)
+		switch part {
+		case "primary":
+			w.Primary = v
+		case "secondary":
+			w.Secondary = v
+		default:
+			return Theme{}, fmt.Errorf("%s: unknown theme setting %q, icons have a primary and a secondary color", path, key)
+		}
+		t.Weather[icon] = w
+	}
+	return t, nil
+}
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&themeFlag, "theme", "auto", "Color theme: auto (dark or light by the terminal background), dark, light, high-contrast, mono or a theme file's name")
+	rootCmd.PersistentFlags().StringVar(&colorFlag, "color", "auto", "Use colors: auto (when the terminal supports them and NO_COLOR is not set), always or never")
+}
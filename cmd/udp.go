@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// DefaultUDPAddr is where Tempest hubs broadcast on the local network.
+const DefaultUDPAddr = ":50222"
+
+// UDPDeviceStatus is a device_status broadcast from the Tempest hub.
+type UDPDeviceStatus struct {
+	Type             string  `json:"type"`
+	SerialNumber     string  `json:"serial_number"`
+	HubSN            string  `json:"hub_sn"`
+	Timestamp        int64   `json:"timestamp"`
+	Uptime           int     `json:"uptime"`
+	Voltage          float64 `json:"voltage"`
+	FirmwareRevision int     `json:"firmware_revision"`
+	RSSI             int     `json:"rssi"`
+	HubRSSI          int     `json:"hub_rssi"`
+	SensorStatus     int     `json:"sensor_status"`
+}
+
+// Parse converts the broadcast to a StatusData.
+func (d UDPDeviceStatus) Parse() StatusData {
+	return StatusData{
+		Timestamp:    time.Unix(d.Timestamp, 0),
+		Kind:         "device",
+		SerialNumber: d.SerialNumber,
+		Uptime:       d.Uptime,
+		Voltage:      d.Voltage,
+		RSSI:         d.RSSI,
+		HubRSSI:      d.HubRSSI,
+		SensorStatus: d.SensorStatus,
+		Firmware:     strconv.Itoa(d.FirmwareRevision),
+	}
+}
+
+// UDPHubStatus is a hub_status broadcast from the Tempest hub. Unlike
+// devices, the hub reports its firmware revision as a string.
+type UDPHubStatus struct {
+	Type             string `json:"type"`
+	SerialNumber     string `json:"serial_number"`
+	FirmwareRevision string `json:"firmware_revision"`
+	Uptime           int    `json:"uptime"`
+	RSSI             int    `json:"rssi"`
+	Timestamp        int64  `json:"timestamp"`
+	ResetFlags       string `json:"reset_flags"`
+	Seq              int    `json:"seq"`
+}
+
+// Parse converts the broadcast to a StatusData.
+func (h UDPHubStatus) Parse() StatusData {
+	return StatusData{
+		Timestamp:    time.Unix(h.Timestamp, 0),
+		Kind:         "hub",
+		SerialNumber: h.SerialNumber,
+		Uptime:       h.Uptime,
+		RSSI:         h.RSSI,
+		Firmware:     h.FirmwareRevision,
+	}
+}
+
+// listenUDP opens the socket hub broadcasts are received on.
+func listenUDP(addr string) (net.PacketConn, error) {
+	conn, err := net.ListenPacket("udp4", addr)
+	if err != nil {
+		return nil, fmt.Errorf("udp listen %s: %w", addr, err)
+	}
+	return conn, nil
+}
+
+// udpReadLoop decodes broadcasts from conn into msgCh with decode until conn
+// is closed. Packets from other devices on the network are accepted too; the
+// hub broadcasts for every device it is paired with.
+func udpReadLoop(conn net.PacketConn, msgCh chan<- tea.Msg, done <-chan struct{}, decode func([]byte) tea.Msg) {
+	buf := make([]byte, 64*1024)
+	for {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			select {
+			case <-done:
+			default:
+				deliver(msgCh, done, wsErrorMsg{err: fmt.Errorf("udp read: %w", err)})
+			}
+			return
+		}
+		if msg := decode(buf[:n]); msg != nil && !deliver(msgCh, done, msg) {
+			return
+		}
+	}
+}
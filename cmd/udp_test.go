@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Hub broadcasts as documented in the Tempest UDP reference.
+const (
+	udpObsSt        = `{"serial_number":"ST-00000512","type":"obs_st","hub_sn":"HB-00013030","obs":[[1588948614,0.18,0.22,0.27,144,6,1017.57,22.37,50.26,328,0.03,3,0.000000,0,0,0,2.410,1]],"firmware_revision":129}`
+	udpRapidWind    = `{"serial_number":"ST-00000512","type":"rapid_wind","hub_sn":"HB-00013030","ob":[1588948614,2.3,128]}`
+	udpStrike       = `{"serial_number":"ST-00000512","type":"evt_strike","hub_sn":"HB-00013030","evt":[1588948614,3,3848]}`
+	udpDeviceStatus = `{"serial_number":"ST-00000512","type":"device_status","hub_sn":"HB-00013030","timestamp":1588948614,"uptime":2189,"voltage":2.41,"firmware_revision":129,"rssi":-17,"hub_rssi":-87,"sensor_status":0,"debug":0}`
+)
+
+func TestUDPReadLoop(t *testing.T) {
+	conn, err := listenUDP("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	msgCh := make(chan tea.Msg, 8)
+	done := make(chan struct{})
+	exited := make(chan struct{})
+	go func() {
+		udpReadLoop(conn, msgCh, done, decodeMessage)
+		close(exited)
+	}()
+
+	hub, err := net.Dial("udp4", conn.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer hub.Close()
+
+	at := time.Unix(1588948614, 0)
+	from := deviceRef{serial: "ST-00000512"}
+	tests := []struct {
+		name   string
+		packet string
+		want   tea.Msg
+	}{
+		{"obs_st", udpObsSt, wsObsMsg{from: from, obs: ParseObsArray([]float64{
+			1588948614, 0.18, 0.22, 0.27, 144, 6, 1017.57, 22.37, 50.26, 328, 0.03, 3, 0, 0, 0, 0, 2.410, 1,
+		})}},
+		{"rapid_wind", udpRapidWind, wsRapidWindMsg{from: from, wind: RapidWindData{Timestamp: at, WindSpeed: 2.3, WindDirection: 128}}},
+		{"evt_strike", udpStrike, wsEventMsg{event: EventData{
+			Timestamp: at, Type: EventLightning, Severity: SeverityAlert, SerialNumber: "ST-00000512", Distance: 3, Energy: 3848,
+		}}},
+		{"device_status", udpDeviceStatus, wsStatusMsg{status: StatusData{
+			Timestamp: at, Kind: "device", SerialNumber: "ST-00000512", Uptime: 2189, Voltage: 2.41,
+			RSSI: -17, HubRSSI: -87, Firmware: "129",
+		}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Packets that do not decode are dropped without a message.
+			if _, err := hub.Write([]byte("not json")); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := hub.Write([]byte(tt.packet)); err != nil {
+				t.Fatal(err)
+			}
+			select {
+			case got := <-msgCh:
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("got %#v\nwant %#v", got, tt.want)
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatal("no message received")
+			}
+		})
+	}
+
+	// Closing the socket once done is closed ends the loop quietly.
+	close(done)
+	conn.Close()
+	select {
+	case <-exited:
+	case <-time.After(2 * time.Second):
+		t.Fatal("read loop did not exit")
+	}
+	select {
+	case msg := <-msgCh:
+		t.Errorf("unexpected message after close: %#v", msg)
+	default:
+	}
+}
@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"tempest-cli/units"
+
+	"github.com/spf13/cobra"
+)
+
+// Unit flags shared by every command that displays measurements.
+var (
+	tempUnitFlag     string
+	windUnitFlag     string
+	pressureUnitFlag string
+	precipUnitFlag   string
+	distanceUnitFlag string
+
+	// Shortcuts kept from before the unit flags existed.
+	useFahrenheit bool
+	useMph        bool
+	useInches     bool
+	useMiles      bool
+)
+
+// displayUnits holds the units measurements are shown in, resolved from the
+// flags and config by resolveUnits before any command runs.
+var displayUnits = units.Metric()
+
+// unitShortcuts pairs each shortcut flag with the unit flag it sets.
+var unitShortcuts = []struct {
+	shortcut string
+	enabled  *bool
+	flag     string
+	value    string
+}{
+	{"fahrenheit", &useFahrenheit, "temp-unit", string(units.Fahrenheit)},
+	{"mph", &useMph, "wind-unit", string(units.MilesPerHour)},
+	{"inches", &useInches, "precip-unit", string(units.Inches)},
+	{"miles", &useMiles, "distance-unit", string(units.Miles)},
+}
+
+// resolveUnits validates the unit flags and stores the result in
+// displayUnits. Shortcut flags such as --fahrenheit override the configured
+// unit but cannot be combined with the matching unit flag.
+func resolveUnits(cmd *cobra.Command) error {
+	flags := map[string]*string{
+		"temp-unit":     &tempUnitFlag,
+		"wind-unit":     &windUnitFlag,
+		"pressure-unit": &pressureUnitFlag,
+		"precip-unit":   &precipUnitFlag,
+		"distance-unit": &distanceUnitFlag,
+	}
+	for _, s := range unitShortcuts {
+		if !*s.enabled {
+			continue
+		}
+		if cmd.Flags().Changed(s.flag) {
+			return fmt.Errorf("--%s cannot be combined with --%s", s.shortcut, s.flag)
+		}
+		*flags[s.flag] = s.value
+	}
+
+	var (
+		p   units.Prefs
+		err error
+	)
+	if p.Temp, err = units.ParseTempUnit(tempUnitFlag); err != nil {
+		return err
+	}
+	if p.Wind, err = units.ParseSpeedUnit(windUnitFlag); err != nil {
+		return err
+	}
+	if p.Pressure, err = units.ParsePressureUnit(pressureUnitFlag); err != nil {
+		return err
+	}
+	if p.Precip, err = units.ParsePrecipUnit(precipUnitFlag); err != nil {
+		return err
+	}
+	if p.Distance, err = units.ParseDistanceUnit(distanceUnitFlag); err != nil {
+		return err
+	}
+	displayUnits = p
+	return nil
+}
+
+// unitSettings validates the value of each units.* config setting.
+var unitSettings = map[string]func(string) error{
+	"units.temp":     func(s string) error { _, err := units.ParseTempUnit(s); return err },
+	"units.wind":     func(s string) error { _, err := units.ParseSpeedUnit(s); return err },
+	"units.pressure": func(s string) error { _, err := units.ParsePressureUnit(s); return err },
+	"units.precip":   func(s string) error { _, err := units.ParsePrecipUnit(s); return err },
+	"units.distance": func(s string) error { _, err := units.ParseDistanceUnit(s); return err },
+}
+
+// validateUnitSetting checks value when key is a units setting, either at the
+// top level or inside a profile.
+func validateUnitSetting(key, value string) error {
+	i := strings.LastIndex(key, "units.")
+	if i < 0 {
+		return nil
+	}
+	if validate, ok := unitSettings[key[i:]]; ok {
+		return validate(value)
+	}
+	return nil
+}
+
+func init() {
+	m := units.Metric()
+	pf := rootCmd.PersistentFlags()
+	pf.StringVar(&tempUnitFlag, "temp-unit", string(m.Temp), "Temperature unit: c, f or k")
+	pf.StringVar(&windUnitFlag, "wind-unit", string(m.Wind), "Wind speed unit: mps, kph, mph, kts or bft")
+	pf.StringVar(&pressureUnitFlag, "pressure-unit", string(m.Pressure), "Pressure unit: mb, hpa, inhg or mmhg")
+	pf.StringVar(&precipUnitFlag, "precip-unit", string(m.Precip), "Precipitation unit: mm, cm or in")
+	pf.StringVar(&distanceUnitFlag, "distance-unit", string(m.Distance), "Distance unit: km or mi")
+	pf.BoolVarP(&useFahrenheit, "fahrenheit", "f", false, "Shortcut for --temp-unit f")
+	pf.BoolVar(&useMph, "mph", false, "Shortcut for --wind-unit mph")
+	pf.BoolVar(&useInches, "inches", false, "Shortcut for --precip-unit in")
+	pf.BoolVar(&useMiles, "miles", false, "Shortcut for --distance-unit mi")
+}
@@ -4,7 +4,8 @@ package cmd
 type WeatherTheme struct {
 	Primary   string // main icon color
 	Secondary string // border / accent color
-	Label     string // stat label color
+	Label     string // stat label color, from the Theme
+	Text      string // value and title color, from the Theme
 }
 
 // WeatherIcon holds ASCII art in two sizes.
@@ -13,26 +14,27 @@ type WeatherIcon struct {
 	Mini []string // 3 lines, ~7 chars wide (daily cards)
 }
 
+// weatherThemes are the weather colors of the dark theme.
 var weatherThemes = map[string]WeatherTheme{
-	"clear-day":              {Primary: "#FFD700", Secondary: "#FFD700", Label: "#888888"},
-	"clear-night":            {Primary: "#C0C0C0", Secondary: "#7B68EE", Label: "#888888"},
-	"partly-cloudy-day":      {Primary: "#FFD700", Secondary: "#A0A0A0", Label: "#888888"},
-	"partly-cloudy-night":    {Primary: "#C0C0C0", Secondary: "#A0A0A0", Label: "#888888"},
-	"cloudy":                 {Primary: "#A0A0A0", Secondary: "#A0A0A0", Label: "#888888"},
-	"rainy":                  {Primary: "#4A90D9", Secondary: "#4A90D9", Label: "#888888"},
-	"possibly-rainy-day":     {Primary: "#4A90D9", Secondary: "#4A90D9", Label: "#888888"},
-	"possibly-rainy-night":   {Primary: "#4A90D9", Secondary: "#4A90D9", Label: "#888888"},
-	"snow":                   {Primary: "#FFFFFF", Secondary: "#87CEEB", Label: "#888888"},
-	"possibly-snow-day":      {Primary: "#FFFFFF", Secondary: "#87CEEB", Label: "#888888"},
-	"possibly-snow-night":    {Primary: "#FFFFFF", Secondary: "#87CEEB", Label: "#888888"},
-	"sleet":                  {Primary: "#87CEEB", Secondary: "#87CEEB", Label: "#888888"},
-	"possibly-sleet-day":     {Primary: "#87CEEB", Secondary: "#87CEEB", Label: "#888888"},
-	"possibly-sleet-night":   {Primary: "#87CEEB", Secondary: "#87CEEB", Label: "#888888"},
-	"thunderstorm":           {Primary: "#FFD700", Secondary: "#9370DB", Label: "#888888"},
-	"possibly-thunderstorm-day":   {Primary: "#FFD700", Secondary: "#9370DB", Label: "#888888"},
-	"possibly-thunderstorm-night": {Primary: "#FFD700", Secondary: "#9370DB", Label: "#888888"},
-	"windy":                  {Primary: "#87CEEB", Secondary: "#87CEEB", Label: "#888888"},
-	"foggy":                  {Primary: "#D3D3D3", Secondary: "#D3D3D3", Label: "#888888"},
+	"clear-day":                   {Primary: "#FFD700", Secondary: "#FFD700"},
+	"clear-night":                 {Primary: "#C0C0C0", Secondary: "#7B68EE"},
+	"partly-cloudy-day":           {Primary: "#FFD700", Secondary: "#A0A0A0"},
+	"partly-cloudy-night":         {Primary: "#C0C0C0", Secondary: "#A0A0A0"},
+	"cloudy":                      {Primary: "#A0A0A0", Secondary: "#A0A0A0"},
+	"rainy":                       {Primary: "#4A90D9", Secondary: "#4A90D9"},
+	"possibly-rainy-day":          {Primary: "#4A90D9", Secondary: "#4A90D9"},
+	"possibly-rainy-night":        {Primary: "#4A90D9", Secondary: "#4A90D9"},
+	"snow":                        {Primary: "#FFFFFF", Secondary: "#87CEEB"},
+	"possibly-snow-day":           {Primary: "#FFFFFF", Secondary: "#87CEEB"},
+	"possibly-snow-night":         {Primary: "#FFFFFF", Secondary: "#87CEEB"},
+	"sleet":                       {Primary: "#87CEEB", Secondary: "#87CEEB"},
+	"possibly-sleet-day":          {Primary: "#87CEEB", Secondary: "#87CEEB"},
+	"possibly-sleet-night":        {Primary: "#87CEEB", Secondary: "#87CEEB"},
+	"thunderstorm":                {Primary: "#FFD700", Secondary: "#9370DB"},
+	"possibly-thunderstorm-day":   {Primary: "#FFD700", Secondary: "#9370DB"},
+	"possibly-thunderstorm-night": {Primary: "#FFD700", Secondary: "#9370DB"},
+	"windy":                       {Primary: "#87CEEB", Secondary: "#87CEEB"},
+	"foggy":                       {Primary: "#D3D3D3", Secondary: "#D3D3D3"},
 }
 
 var weatherIcons = map[string]WeatherIcon{
@@ -193,10 +195,19 @@ var weatherIcons = map[string]WeatherIcon{
 }
 
 func getWeatherTheme(icon string) WeatherTheme {
-	if t, ok := weatherThemes[icon]; ok {
-		return t
-	}
-	return weatherThemes["cloudy"]
+	return currentTheme().weather(icon)
+}
+
+// iconAliases maps variant icons to their base art and colors.
+var iconAliases = map[string]string{
+	"possibly-rainy-day":          "rainy",
+	"possibly-rainy-night":        "rainy",
+	"possibly-snow-day":           "snow",
+	"possibly-snow-night":         "snow",
+	"possibly-sleet-day":          "sleet",
+	"possibly-sleet-night":        "sleet",
+	"possibly-thunderstorm-day":   "thunderstorm",
+	"possibly-thunderstorm-night": "thunderstorm",
 }
 
 func getWeatherIcon(icon string) WeatherIcon {
@@ -205,17 +216,7 @@ func getWeatherIcon(icon string) WeatherIcon {
 		return ic
 	}
 	// Map variant icons to their base art
-	aliases := map[string]string{
-		"possibly-rainy-day":           "rainy",
-		"possibly-rainy-night":         "rainy",
-		"possibly-snow-day":            "snow",
-		"possibly-snow-night":          "snow",
-		"possibly-sleet-day":           "sleet",
-		"possibly-sleet-night":         "sleet",
-		"possibly-thunderstorm-day":    "thunderstorm",
-		"possibly-thunderstorm-night":  "thunderstorm",
-	}
-	if base, ok := aliases[icon]; ok {
+	if base, ok := iconAliases[icon]; ok {
 		return weatherIcons[base]
 	}
 	return weatherIcons["cloudy"]
@@ -1,111 +1,359 @@
 package cmd
 
 import (
-	"encoding/json"
+	"cmp"
+	"context"
 	"fmt"
-	"io/ioutil"
-	"net/http"
-	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"tempest-cli/config"
+	"tempest-cli/tempest"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
 )
 
 var (
-	wsTemperatureAsFahrenheit bool
-	wsDistanceAsMiles         bool
-	wsPrecipAsInches          bool
-	wsWindAsMph               bool
+	wsSource  string
+	wsUDPAddr string
+	wsRecord  string
+	wsReplay  string
+	wsSpeed   string
+	wsGrid    bool
+	wsLayout  string
+
+	wsChartWindow time.Duration
+	wsChartStyle  string
 )
 
 var websocketCmd = &cobra.Command{
-	Use:   "websocket",
-	Short: "Live weather dashboard via WebSocket",
+	Use:     "websocket",
+	Aliases: []string{"live"},
+	Short:   "Live weather dashboard via WebSocket",
 	Long: `Connect to the Tempest WebSocket API for a real-time weather dashboard.
 Displays live observations (~60s), rapid wind (~3s), and weather events
 (lightning, rain) in a full-screen terminal UI.
 
-Press q to quit.`,
-	Run: func(cmd *cobra.Command, args []string) {
-		sid := cmd.Flag("station").Value.String()
-		if sid == "" {
-			fmt.Println("Station ID is required. Use -s <station_id>")
-			return
-		}
+-s takes several stations, separated by commas, or the name of a group
+saved with "tempest-cli profile group"; every Tempest of every station is
+watched over the one connection. Switch stations with tab, shift+tab or
+1-9, and press g to show them all as tiles.
 
-		apiToken := getAPIToken()
+The panels are laid out to fit the terminal: side by side on wide terminals,
+stacked on medium ones, and as a line per metric on small ones. --layout
+picks one whatever the size.
 
-		// Fetch station info to get name, timezone, device ID
-		station, err := fetchStationInfo(apiToken, sid)
-		if err != nil {
-			fmt.Printf("Error fetching station info: %v\n", err)
-			return
+The trends panel charts temperature, humidity, pressure, wind or solar
+radiation over the last --chart-window (3h by default, up to 24h); press c to
+switch between them. The window is filled from the REST API at startup.
+
+The forecast strip shows the next 12 hours of the station's forecast,
+refreshed every 20 minutes; [ and ] scroll it when it does not fit.
+
+With --source udp the dashboard instead listens for the broadcasts a Tempest
+hub sends on the local network (UDP port 50222). This needs no API token and
+no internet connection.
+
+--record saves every message received to a file, one JSON object per line
+with the time it arrived. --replay plays such a file back into the
+dashboard, at its original pace or faster with --speed, to review past
+weather or reproduce a problem.
+
+Press ? for the keys, which include switching units, pausing and
+reconnecting, and q to quit. Keys can be rebound under [keys] in the config
+file.`,
+	Example: `  tempest-cli websocket -s 12345
+  tempest-cli live -s roof,field1,67890 --grid
+  tempest-cli live --source udp
+  tempest-cli websocket -s 12345 --record storm.ndjson
+  tempest-cli websocket --replay storm.ndjson --speed 10x`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := validateLayout(wsLayout); err != nil {
+			return err
+		}
+		if wsChartWindow < minChartWindow || wsChartWindow > maxChartWindow {
+			return fmt.Errorf("invalid --chart-window %s, use a duration from %s to %s", wsChartWindow, shortDuration(minChartWindow), shortDuration(maxChartWindow))
+		}
+		if wsChartStyle != "braille" && wsChartStyle != "block" {
+			return fmt.Errorf("invalid --chart-style %q, valid styles are: braille, block", wsChartStyle)
+		}
+		if wsReplay != "" {
+			return runReplay(cmd)
+		}
+		if cmd.Flags().Changed("speed") {
+			return fmt.Errorf("--speed only applies to --replay")
 		}
 
-		if len(station.Stations) == 0 {
-			fmt.Println("No station found for the given ID")
-			return
+		var recorder *sessionRecorder
+		if wsRecord != "" {
+			f, err := os.Create(wsRecord)
+			if err != nil {
+				return fmt.Errorf("creating recording: %w", err)
+			}
+			defer f.Close()
+			recorder = newSessionRecorder(f)
 		}
 
-		s := station.Stations[0]
-		deviceID := extractTempestDeviceID(station)
-		if deviceID == 0 {
-			fmt.Println("No Tempest device found for this station")
-			return
+		switch wsSource {
+		case "ws":
+		case "udp":
+			return runRecordedDashboard(DashboardModel{
+				stations: []*stationState{{name: "Tempest hub", source: "UDP " + wsUDPAddr}},
+				grid:     wsGrid,
+				source:   "udp",
+				udpAddr:  wsUDPAddr,
+				recorder: recorder,
+			})
+		default:
+			return fmt.Errorf("invalid --source %q, valid sources are: ws, udp", wsSource)
 		}
 
-		model := DashboardModel{
-			stationName: s.Name,
-			timezone:    s.Timezone,
-			deviceID:    deviceID,
-			apiToken:    apiToken,
-			unitPrefs: UnitPrefs{
-				TempF:    wsTemperatureAsFahrenheit,
-				WindMph:  wsWindAsMph,
-				PrecipIn: wsPrecipAsInches,
-				DistMi:   wsDistanceAsMiles,
-			},
-			wsDone: make(chan struct{}),
-			msgCh:  make(chan tea.Msg, 32),
+		apiToken, err := getAPIToken()
+		if err != nil {
+			return err
+		}
+		sids, err := dashboardStations(cmd.Flag("station").Value.String(), apiToken)
+		if err != nil {
+			return err
 		}
 
-		p := tea.NewProgram(model, tea.WithAltScreen())
-		if _, err := p.Run(); err != nil {
-			fmt.Printf("Error running dashboard: %v\n", err)
+		// Fetch station info to get names, timezones and device IDs
+		var stations []*stationState
+		for _, sid := range sids {
+			station, err := fetchStationInfo(cmd.Context(), apiToken, sid)
+			if err != nil {
+				return fmt.Errorf("fetching station info: %w", err)
+			}
+			states := tempestStates(station)
+			if len(states) == 0 {
+				return fmt.Errorf("no Tempest device found for station %d", sid)
+			}
+			stations = append(stations, states...)
 		}
+
+		return runRecordedDashboard(DashboardModel{
+			stations: stations,
+			grid:     wsGrid && len(stations) > 1,
+			apiToken: apiToken,
+			source:   "ws",
+			recorder: recorder,
+		})
 	},
 }
 
-func fetchStationInfo(token, stationID string) (*Station, error) {
-	baseURL := "https://swd.weatherflow.com/swd/rest/stations/" + stationID
-	params := url.Values{}
-	params.Add("token", token)
-
-	req, err := http.NewRequest("GET", baseURL, nil)
+// dashboardStations expands the -s value of the live dashboard, a
+// comma-separated list of station IDs, profile aliases and group names,
+// into station IDs. Every station shares the one connection, so a profile
+// with a token other than token cannot be part of the list.
+func dashboardStations(s, token string) ([]int, error) {
+	cfg, err := loadConfig()
 	if err != nil {
-		return nil, fmt.Errorf("creating request: %w", err)
+		return nil, fmt.Errorf("loading config: %w", err)
+	}
+
+	var names []string
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(name)
+		if members, ok := cfg.Group(name); ok {
+			names = append(names, members...)
+		} else if name != "" {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("Station ID is required. Use -s <station_id>")
+	}
+
+	var ids []int
+	seen := map[int]bool{}
+	for _, name := range names {
+		sid, alias, err := resolveStation(cfg, name)
+		if err != nil {
+			return nil, err
+		}
+		if t, _ := cfg.Get(config.ProfileKey(alias, "token")); alias != "" && t != "" && t != token {
+			return nil, fmt.Errorf("profile %q uses its own API token; stations on one dashboard must share a token", alias)
+		}
+		id, err := tempest.ParseStationID(sid)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// tempestStates returns a dashboard state for every Tempest device of the
+// station, or for its first device when it has none. A station with several
+// Tempests names each after its serial number.
+func tempestStates(station *tempest.Station) []*stationState {
+	if len(station.Stations) == 0 {
+		return nil
+	}
+	s := station.Stations[0]
+	var states []*stationState
+	for _, device := range s.Devices {
+		if device.DeviceType == "ST" {
+			states = append(states, &stationState{
+				name:      s.Name,
+				timezone:  s.Timezone,
+				stationID: s.StationID,
+				deviceID:  device.DeviceID,
+				serial:    device.SerialNumber,
+			})
+		}
+	}
+	if len(states) == 0 && len(s.Devices) > 0 {
+		d := s.Devices[0]
+		states = append(states, &stationState{name: s.Name, timezone: s.Timezone, stationID: s.StationID, deviceID: d.DeviceID, serial: d.SerialNumber})
 	}
-	req.URL.RawQuery = params.Encode()
+	if len(states) > 1 {
+		for _, st := range states {
+			st.name += " " + cmp.Or(st.serial, strconv.Itoa(st.deviceID))
+		}
+	}
+	return states
+}
 
-	resp, err := http.DefaultClient.Do(req)
+// runReplay runs the dashboard on the session recorded in --replay.
+func runReplay(cmd *cobra.Command) error {
+	for _, name := range []string{"record", "source", "udp-addr"} {
+		if cmd.Flags().Changed(name) {
+			return fmt.Errorf("--replay cannot be combined with --%s", name)
+		}
+	}
+	speed, err := parseReplaySpeed(wsSpeed)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(wsReplay)
 	if err != nil {
-		return nil, fmt.Errorf("making request: %w", err)
+		return fmt.Errorf("opening recording: %w", err)
 	}
-	defer resp.Body.Close()
+	defer f.Close()
 
-	body, err := ioutil.ReadAll(resp.Body)
+	return runDashboard(DashboardModel{
+		stations:    []*stationState{{name: "Replay", source: filepath.Base(wsReplay)}},
+		grid:        wsGrid,
+		source:      "replay",
+		replay:      f,
+		replaySpeed: speed,
+	})
+}
+
+// runRecordedDashboard runs the dashboard and reports a recording that
+// failed part way.
+func runRecordedDashboard(model DashboardModel) error {
+	if err := runDashboard(model); err != nil {
+		return err
+	}
+	if model.recorder != nil {
+		if err := model.recorder.Err(); err != nil {
+			return fmt.Errorf("recording stopped early: %w", err)
+		}
+	}
+	return nil
+}
+
+// validateLayout checks the value of --layout.
+func validateLayout(layout string) error {
+	names := []string{"auto"}
+	for _, l := range dashLayouts {
+		names = append(names, string(l))
+	}
+	if !slices.Contains(names, layout) {
+		return fmt.Errorf("invalid --layout %q, valid layouts are: %s", layout, strings.Join(names, ", "))
+	}
+	return nil
+}
+
+// runDashboard completes model with the shared settings and runs it until
+// the user quits.
+func runDashboard(model DashboardModel) error {
+	cfg, err := loadConfig()
 	if err != nil {
-		return nil, fmt.Errorf("reading response: %w", err)
+		return fmt.Errorf("loading config: %w", err)
+	}
+	if model.keys, err = loadKeyMap(cfg); err != nil {
+		return err
+	}
+	model.units = displayUnits
+	model.layout = wsLayout
+	model.chartWindow = wsChartWindow
+	model.chartStyle = wsChartStyle
+	model.msgCh = make(chan tea.Msg, 32)
+	model.ctx, model.cancel = context.WithCancel(context.Background())
+	defer model.cancel()
+	if model.source == "ws" {
+		var deviceIDs []int
+		for _, st := range model.stations {
+			deviceIDs = append(deviceIDs, st.deviceID)
+		}
+		model.ws = newLiveWSClient(model.apiToken, model.msgCh, model.ctx.Done(), deviceIDs...)
 	}
 
-	var station Station
-	if err := json.Unmarshal(body, &station); err != nil {
-		return nil, fmt.Errorf("parsing response: %w", err)
+	// Settle the theme first: with --theme auto it asks the terminal for its
+	// background, and the reply must not reach the dashboard as keypresses.
+	currentTheme()
+	p := tea.NewProgram(model, tea.WithAltScreen())
+	if _, err := p.Run(); err != nil {
+		return fmt.Errorf("running dashboard: %w", err)
 	}
-	return &station, nil
+	return nil
+}
+
+// newLiveWSClient returns a WebSocket client subscribed to deviceIDs that
+// reports its connection state to msgCh as wsStateMsg until done is closed.
+func newLiveWSClient(token string, msgCh chan<- tea.Msg, done <-chan struct{}, deviceIDs ...int) *tempest.WSClient {
+	ws := tempest.NewWSClient(token,
+		tempest.WithWSURL(liveWSURL()),
+		tempest.WithStateHandler(func(change tempest.WSStateChange) {
+			deliver(msgCh, done, wsStateMsg{change: change})
+		}))
+	for _, id := range deviceIDs {
+		ws.Subscribe(id)
+	}
+	return ws
+}
+
+// runWSClient runs ws until ctx is cancelled, delivering the messages it
+// receives to msgCh after decoding them with decode. An error the client
+// cannot recover from is delivered as a wsErrorMsg.
+func runWSClient(ctx context.Context, ws *tempest.WSClient, msgCh chan<- tea.Msg, decode func([]byte) tea.Msg) {
+	err := ws.Run(ctx, func(raw []byte) {
+		if msg := decode(raw); msg != nil {
+			deliver(msgCh, ctx.Done(), msg)
+		}
+	})
+	if ctx.Err() == nil {
+		deliver(msgCh, ctx.Done(), wsErrorMsg{err: err})
+	}
+}
+
+// deliver sends msg on msgCh unless done is closed first, so a source never
+// blocks on a consumer that has gone away. It reports whether msg was sent.
+func deliver(msgCh chan<- tea.Msg, done <-chan struct{}, msg tea.Msg) bool {
+	select {
+	case msgCh <- msg:
+		return true
+	case <-done:
+		return false
+	}
+}
+
+func fetchStationInfo(ctx context.Context, token string, stationID int) (*tempest.Station, error) {
+	return newAPIClientWithToken(token).GetStation(ctx, stationID)
 }
 
-func extractTempestDeviceID(station *Station) int {
+func extractTempestDeviceID(station *tempest.Station) int {
 	if len(station.Stations) == 0 {
 		return 0
 	}
@@ -125,8 +373,13 @@ func extractTempestDeviceID(station *Station) int {
 func init() {
 	rootCmd.AddCommand(websocketCmd)
 
-	websocketCmd.Flags().BoolVarP(&wsTemperatureAsFahrenheit, "fahrenheit", "f", false, "Display temperature in Fahrenheit")
-	websocketCmd.Flags().BoolVarP(&wsDistanceAsMiles, "miles", "", false, "Display distance in Miles")
-	websocketCmd.Flags().BoolVarP(&wsPrecipAsInches, "inches", "", false, "Display precipitation in Inches")
-	websocketCmd.Flags().BoolVarP(&wsWindAsMph, "mph", "", false, "Display wind speed in MPH")
+	websocketCmd.Flags().StringVar(&wsSource, "source", "ws", "Data source: ws (Tempest WebSocket API) or udp (local hub broadcasts)")
+	websocketCmd.Flags().StringVar(&wsUDPAddr, "udp-addr", DefaultUDPAddr, "Address to listen on for hub broadcasts with --source udp")
+	websocketCmd.Flags().StringVar(&wsRecord, "record", "", "Save every message received to this file as NDJSON")
+	websocketCmd.Flags().StringVar(&wsReplay, "replay", "", "Play back a session saved with --record instead of connecting")
+	websocketCmd.Flags().StringVar(&wsSpeed, "speed", "1", "Playback speed-up for --replay, e.g. 10 or 10x, or 0 for no pauses")
+	websocketCmd.Flags().BoolVar(&wsGrid, "grid", false, "Start with every station shown as a tile instead of in tabs")
+	websocketCmd.Flags().StringVar(&wsLayout, "layout", "auto", "Panel layout: auto (by terminal size), columns, stacked or compact")
+	websocketCmd.Flags().DurationVar(&wsChartWindow, "chart-window", defaultChartWindow, "Span of observations the trend charts show, from 1h to 24h")
+	websocketCmd.Flags().StringVar(&wsChartStyle, "chart-style", "braille", "Trend chart style: braille (a line) or block (bars, for fonts without braille)")
 }
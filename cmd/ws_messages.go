@@ -1,20 +1,13 @@
 package cmd
 
-import "time"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
-// --- Outbound WS messages ---
-
-type WSListenStart struct {
-	Type     string `json:"type"`
-	DeviceID int    `json:"device_id"`
-	ID       string `json:"id"`
-}
-
-type WSListenRapidStart struct {
-	Type     string `json:"type"`
-	DeviceID int    `json:"device_id"`
-	ID       string `json:"id"`
-}
+	"tempest-cli/units"
+)
 
 // --- Inbound WS messages ---
 
@@ -23,32 +16,50 @@ type WSMessage struct {
 	Type string `json:"type"`
 }
 
-// WSObservation is an obs_st message from the Tempest WS.
+// WSObservation is an obs_st message from the Tempest WS. Hub broadcasts
+// carry the serial number instead of the device ID.
 type WSObservation struct {
-	Type     string      `json:"type"`
-	DeviceID int         `json:"device_id"`
-	Obs      [][]float64 `json:"obs"`
+	Type         string      `json:"type"`
+	DeviceID     int         `json:"device_id"`
+	SerialNumber string      `json:"serial_number"`
+	Obs          [][]float64 `json:"obs"`
+	Summary      *ObsSummary `json:"summary"`
 }
 
 // WSRapidWind is a rapid_wind message from the Tempest WS.
 type WSRapidWind struct {
-	Type     string    `json:"type"`
-	DeviceID int       `json:"device_id"`
-	Ob       []float64 `json:"ob"`
+	Type         string    `json:"type"`
+	DeviceID     int       `json:"device_id"`
+	SerialNumber string    `json:"serial_number"`
+	Ob           []float64 `json:"ob"`
 }
 
-// WSEventPrecip is an evt_precip message from the Tempest WS.
+// WSEventPrecip is an evt_precip message, sent when rain starts. Evt is
+// [epoch].
 type WSEventPrecip struct {
-	Type     string    `json:"type"`
-	DeviceID int       `json:"device_id"`
-	Evt      []float64 `json:"evt"`
+	Type         string    `json:"type"`
+	DeviceID     int       `json:"device_id"`
+	SerialNumber string    `json:"serial_number"`
+	Evt          []float64 `json:"evt"`
 }
 
-// WSEventStrike is an evt_strike message from the Tempest WS.
+// WSEventStrike is an evt_strike message. Evt is [epoch, distance_km,
+// energy].
 type WSEventStrike struct {
-	Type     string    `json:"type"`
-	DeviceID int       `json:"device_id"`
-	Evt      []float64 `json:"evt"`
+	Type         string    `json:"type"`
+	DeviceID     int       `json:"device_id"`
+	SerialNumber string    `json:"serial_number"`
+	Evt          []float64 `json:"evt"`
+}
+
+// WSEventOnline is an evt_device_online, evt_device_offline,
+// evt_station_online or evt_station_offline message. Evt is [epoch].
+type WSEventOnline struct {
+	Type         string    `json:"type"`
+	DeviceID     int       `json:"device_id"`
+	StationID    int       `json:"station_id"`
+	SerialNumber string    `json:"serial_number"`
+	Evt          []float64 `json:"evt"`
 }
 
 // WSAck is an acknowledgment message from the Tempest WS.
@@ -61,37 +72,283 @@ type WSAck struct {
 
 // ObsData holds named fields parsed from the obs_st 22-element array.
 type ObsData struct {
-	Timestamp      time.Time
-	WindLull       float64
-	WindAvg        float64
-	WindGust       float64
-	WindDirection  int
-	Pressure       float64
-	Temperature    float64
-	Humidity       float64
-	Illuminance    float64
-	UV             float64
-	SolarRadiation float64
-	PrecipAccum    float64
-	PrecipType     int
-	LightningDist  float64
-	LightningCount int
-	Battery        float64
-	DailyRain      float64
+	Timestamp      time.Time `json:"timestamp"`
+	WindLull       float64   `json:"wind_lull"`
+	WindAvg        float64   `json:"wind_avg"`
+	WindGust       float64   `json:"wind_gust"`
+	WindDirection  int       `json:"wind_direction"`
+	Pressure       float64   `json:"pressure"`
+	Temperature    float64   `json:"temperature"`
+	Humidity       float64   `json:"humidity"`
+	Illuminance    float64   `json:"illuminance"`
+	UV             float64   `json:"uv"`
+	SolarRadiation float64   `json:"solar_radiation"`
+	PrecipAccum    float64   `json:"precip_accum"`
+	PrecipType     int       `json:"precip_type"` // see PrecipTypeName
+	LightningDist  float64   `json:"lightning_distance"`
+	LightningCount int       `json:"lightning_count"`
+	Battery        float64   `json:"battery"`
+	DailyRain      float64   `json:"daily_rain"`
+	WindInterval   int       `json:"wind_interval"`   // seconds
+	ReportInterval int       `json:"report_interval"` // minutes
+
+	// Summary holds the values the server derives; nil for observations
+	// that did not come from the Tempest WS.
+	Summary *ObsSummary `json:"summary,omitempty"`
+}
+
+// precipTypeNames names the precipitation types of obs_st. Rain and hail
+// together is experimental on the Tempest.
+var precipTypeNames = []string{"none", "rain", "hail", "rain+hail"}
+
+// PrecipTypeName returns the observation's precipitation type: "none",
+// "rain", "hail" or "rain+hail".
+func (o ObsData) PrecipTypeName() string {
+	if o.PrecipType < 0 || o.PrecipType >= len(precipTypeNames) {
+		return "none"
+	}
+	return precipTypeNames[o.PrecipType]
+}
+
+// ObsSummary is the summary block the Tempest WS adds to obs_st messages.
+// Fields the server leaves out are nil. Values are metric.
+type ObsSummary struct {
+	PressureTrend      string   `json:"pressure_trend,omitempty"` // "rising", "falling" or "steady"
+	FeelsLike          *float64 `json:"feels_like,omitempty"`
+	HeatIndex          *float64 `json:"heat_index,omitempty"`
+	WindChill          *float64 `json:"wind_chill,omitempty"`
+	DewPoint           *float64 `json:"dew_point,omitempty"`
+	WetBulb            *float64 `json:"wet_bulb_temperature,omitempty"`
+	AirDensity         *float64 `json:"air_density,omitempty"` // kg/m³
+	StrikeCount1h      *int     `json:"strike_count_1h,omitempty"`
+	StrikeCount3h      *int     `json:"strike_count_3h,omitempty"`
+	StrikeLastDist     *float64 `json:"strike_last_dist,omitempty"`
+	StrikeLastEpoch    *int64   `json:"strike_last_epoch,omitempty"`
+	PrecipTotal1h      *float64 `json:"precip_total_1h,omitempty"`
+	PrecipYesterday    *float64 `json:"precip_accum_local_yesterday,omitempty"`
+	PrecipMinutesToday *int     `json:"precip_minutes_local_day,omitempty"`
 }
 
 // RapidWindData holds parsed rapid_wind data.
 type RapidWindData struct {
-	Timestamp     time.Time
-	WindSpeed     float64
-	WindDirection int
+	Timestamp     time.Time `json:"timestamp"`
+	WindSpeed     float64   `json:"wind_speed"`
+	WindDirection int       `json:"wind_direction"`
 }
 
-// EventData represents a weather event (lightning, rain start).
+// EventSeverity ranks an event for display.
+type EventSeverity string
+
+const (
+	SeverityInfo    EventSeverity = "info"
+	SeverityWarning EventSeverity = "warning"
+	SeverityAlert   EventSeverity = "alert"
+)
+
+// Event types of EventData.
+const (
+	EventRain           = "rain"
+	EventLightning      = "lightning"
+	EventDeviceOnline   = "device_online"
+	EventDeviceOffline  = "device_offline"
+	EventStationOnline  = "station_online"
+	EventStationOffline = "station_offline"
+	EventSensor         = "sensor"
+)
+
+// nearbyStrikeKm is the distance within which a lightning strike is an
+// alert rather than a warning.
+const nearbyStrikeKm = 10
+
+// EventData represents an event: rain starting, a lightning strike, a
+// device or station going offline or coming back, or a change of sensor
+// health.
 type EventData struct {
-	Timestamp time.Time
-	Type      string
-	Detail    string
+	Timestamp    time.Time     `json:"timestamp"`
+	Type         string        `json:"type"`
+	Severity     EventSeverity `json:"severity"`
+	DeviceID     int           `json:"device_id,omitempty"`
+	StationID    int           `json:"station_id,omitempty"`
+	SerialNumber string        `json:"serial_number,omitempty"`
+	Distance     float64       `json:"distance,omitempty"` // lightning, km
+	Energy       float64       `json:"energy,omitempty"`   // lightning, unitless
+	Detail       string        `json:"detail,omitempty"`
+}
+
+// Describe returns a one-line description of e with distances in the units
+// of u.
+func (e EventData) Describe(u units.Prefs) string {
+	switch e.Type {
+	case EventRain:
+		return "Rain started"
+	case EventLightning:
+		return fmt.Sprintf("Lightning %s away, energy %.0f", u.FormatDistance(units.Distance(e.Distance)), e.Energy)
+	case EventDeviceOnline, EventDeviceOffline:
+		name := e.SerialNumber
+		if name == "" {
+			name = strconv.Itoa(e.DeviceID)
+		}
+		if e.Type == EventDeviceOffline {
+			return "Device " + name + " went offline"
+		}
+		return "Device " + name + " back online"
+	case EventStationOnline:
+		return fmt.Sprintf("Station %d back online", e.StationID)
+	case EventStationOffline:
+		return fmt.Sprintf("Station %d went offline", e.StationID)
+	}
+	return e.Detail
+}
+
+// In returns e with its distance converted from km to the units of u.
+func (e EventData) In(u units.Prefs) EventData {
+	e.Distance = units.Distance(e.Distance).In(u.Distance)
+	return e
+}
+
+// eventTime returns the epoch that starts every evt array, or now when it
+// is missing.
+func eventTime(evt []float64) time.Time {
+	if len(evt) > 0 {
+		return time.Unix(int64(evt[0]), 0)
+	}
+	return time.Now()
+}
+
+// Parse converts the message to an EventData.
+func (e WSEventPrecip) Parse() EventData {
+	return EventData{
+		Timestamp:    eventTime(e.Evt),
+		Type:         EventRain,
+		Severity:     SeverityInfo,
+		DeviceID:     e.DeviceID,
+		SerialNumber: e.SerialNumber,
+	}
+}
+
+// Parse converts the message to an EventData. Strikes within
+// nearbyStrikeKm are alerts; a strike without a distance is only info.
+func (e WSEventStrike) Parse() EventData {
+	ev := EventData{
+		Timestamp:    eventTime(e.Evt),
+		Type:         EventLightning,
+		Severity:     SeverityInfo,
+		DeviceID:     e.DeviceID,
+		SerialNumber: e.SerialNumber,
+	}
+	if len(e.Evt) < 3 {
+		return ev
+	}
+	ev.Distance = e.Evt[1]
+	ev.Energy = e.Evt[2]
+	ev.Severity = SeverityWarning
+	if ev.Distance <= nearbyStrikeKm {
+		ev.Severity = SeverityAlert
+	}
+	return ev
+}
+
+// Parse converts the message to an EventData. Going offline is an alert.
+func (e WSEventOnline) Parse() EventData {
+	ev := EventData{
+		Timestamp:    eventTime(e.Evt),
+		Type:         strings.TrimPrefix(e.Type, "evt_"),
+		Severity:     SeverityInfo,
+		DeviceID:     e.DeviceID,
+		StationID:    e.StationID,
+		SerialNumber: e.SerialNumber,
+	}
+	if strings.HasSuffix(ev.Type, "_offline") {
+		ev.Severity = SeverityAlert
+	}
+	return ev
+}
+
+// StatusData holds a parsed device_status or hub_status broadcast.
+type StatusData struct {
+	Timestamp    time.Time `json:"timestamp"`
+	Kind         string    `json:"kind"` // "device" or "hub"
+	SerialNumber string    `json:"serial_number"`
+	Uptime       int       `json:"uptime"`
+	Voltage      float64   `json:"voltage,omitempty"`
+	RSSI         int       `json:"rssi"`
+	HubRSSI      int       `json:"hub_rssi,omitempty"`
+	SensorStatus int       `json:"sensor_status,omitempty"`
+	Firmware     string    `json:"firmware_revision"`
+}
+
+// sensorStatusBits names the failure flags of a device_status sensor_status.
+var sensorStatusBits = []struct {
+	bit  int
+	name string
+}{
+	{0x001, "lightning"},
+	{0x002, "lightning noise"},
+	{0x004, "lightning disturber"},
+	{0x008, "pressure"},
+	{0x010, "temperature"},
+	{0x020, "humidity"},
+	{0x040, "wind"},
+	{0x080, "precipitation"},
+	{0x100, "light/UV"},
+}
+
+// SensorFaults lists the sensors a device_status reports as failed.
+func (s StatusData) SensorFaults() []string {
+	var faults []string
+	for _, b := range sensorStatusBits {
+		if s.SensorStatus&b.bit != 0 {
+			faults = append(faults, b.name)
+		}
+	}
+	return faults
+}
+
+// In returns o with its measurements converted from metric to the units of
+// u, for output that carries plain numbers.
+func (o ObsData) In(u units.Prefs) ObsData {
+	o.WindLull = units.Speed(o.WindLull).In(u.Wind)
+	o.WindAvg = units.Speed(o.WindAvg).In(u.Wind)
+	o.WindGust = units.Speed(o.WindGust).In(u.Wind)
+	o.Pressure = units.Pressure(o.Pressure).In(u.Pressure)
+	o.Temperature = units.Temperature(o.Temperature).In(u.Temp)
+	o.PrecipAccum = units.Precip(o.PrecipAccum).In(u.Precip)
+	o.DailyRain = units.Precip(o.DailyRain).In(u.Precip)
+	o.LightningDist = units.Distance(o.LightningDist).In(u.Distance)
+	if o.Summary != nil {
+		o.Summary = o.Summary.In(u)
+	}
+	return o
+}
+
+// In returns a copy of s with its measurements converted from metric to the
+// units of u.
+func (s *ObsSummary) In(u units.Prefs) *ObsSummary {
+	c := *s
+	convert := func(v *float64, in func(float64) float64) *float64 {
+		if v == nil {
+			return nil
+		}
+		out := in(*v)
+		return &out
+	}
+	temp := func(v float64) float64 { return units.Temperature(v).In(u.Temp) }
+	precip := func(v float64) float64 { return units.Precip(v).In(u.Precip) }
+	c.FeelsLike = convert(s.FeelsLike, temp)
+	c.HeatIndex = convert(s.HeatIndex, temp)
+	c.WindChill = convert(s.WindChill, temp)
+	c.DewPoint = convert(s.DewPoint, temp)
+	c.WetBulb = convert(s.WetBulb, temp)
+	c.StrikeLastDist = convert(s.StrikeLastDist, func(v float64) float64 { return units.Distance(v).In(u.Distance) })
+	c.PrecipTotal1h = convert(s.PrecipTotal1h, precip)
+	c.PrecipYesterday = convert(s.PrecipYesterday, precip)
+	return &c
+}
+
+// In returns w with its speed converted from m/s to the units of u.
+func (w RapidWindData) In(u units.Prefs) RapidWindData {
+	w.WindSpeed = units.Speed(w.WindSpeed).In(u.Wind)
+	return w
 }
 
 // ParseObsArray converts the obs_st float64 array to named fields.
@@ -115,6 +372,7 @@ func ParseObsArray(obs []float64) ObsData {
 		WindAvg:        get(2),
 		WindGust:       get(3),
 		WindDirection:  int(get(4)),
+		WindInterval:   int(get(5)),
 		Pressure:       get(6),
 		Temperature:    get(7),
 		Humidity:       get(8),
@@ -126,6 +384,7 @@ func ParseObsArray(obs []float64) ObsData {
 		LightningDist:  get(14),
 		LightningCount: int(get(15)),
 		Battery:        get(16),
+		ReportInterval: int(get(17)),
 		DailyRain:      get(18),
 	}
 }
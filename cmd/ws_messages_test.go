@@ -0,0 +1,36 @@
+package cmd
+
+import "testing"
+
+func TestWSEventStrikeParse(t *testing.T) {
+	tests := []struct {
+		name         string
+		evt          []float64
+		wantSeverity EventSeverity
+		wantDistance float64
+	}{
+		{"far", []float64{1588948614, 27, 3848}, SeverityWarning, 27},
+		{"nearby", []float64{1588948614, nearbyStrikeKm, 3848}, SeverityAlert, nearbyStrikeKm},
+		{"no distance", []float64{1588948614}, SeverityInfo, 0},
+		{"empty", nil, SeverityInfo, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ev := WSEventStrike{Evt: tt.evt}.Parse()
+			if ev.Severity != tt.wantSeverity || ev.Distance != tt.wantDistance {
+				t.Errorf("got %s at %v km, want %s at %v km", ev.Severity, ev.Distance, tt.wantSeverity, tt.wantDistance)
+			}
+		})
+	}
+
+	// Decoded messages without a distance are dropped rather than tracked
+	// as a strike overhead.
+	for _, raw := range []string{
+		`{"type":"evt_strike","device_id":1,"evt":[1588948614]}`,
+		`{"type":"evt_strike","device_id":1,"evt":[]}`,
+	} {
+		if msg := decodeMessage([]byte(raw)); msg != nil {
+			t.Errorf("decodeMessage(%s) = %#v, want nil", raw, msg)
+		}
+	}
+}
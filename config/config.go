@@ -0,0 +1,342 @@
+// Package config reads and writes the tempest-cli configuration file.
+//
+// The file lives at $XDG_CONFIG_HOME/tempest-cli/config.toml (or
+// config.yaml) and holds a small tree of settings. Settings are addressed by
+// dotted keys such as "units.temp", which map to a [units] table in TOML or a
+// nested units: mapping in YAML.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Format is the syntax of a configuration file.
+type Format int
+
+const (
+	TOML Format = iota
+	YAML
+)
+
+// Key describes a setting that may appear in the configuration file.
+type Key struct {
+	Name        string
+	Description string
+}
+
+// Keys lists every supported setting.
+var Keys = []Key{
+	{"token", "Tempest API personal access token"},
+	{"station", "Default station ID used when -s is not given"},
+	{"units.temp", "Temperature unit: c, f or k"},
+	{"units.wind", "Wind speed unit: mps, kph, mph, kts or bft"},
+	{"units.pressure", "Pressure unit: mb, hpa, inhg or mmhg"},
+	{"units.precip", "Precipitation unit: mm, cm or in"},
+	{"units.distance", "Distance unit: km or mi"},
+	{"theme", "Color theme: auto, dark, light, high-contrast, mono or a theme file's name"},
+	{"color", "Use colors: auto, always or never"},
+	{"cache.enabled", "Use the on-disk response cache: true or false"},
+	{"cache.max_age", "Maximum age of cached responses, e.g. 5m"},
+	{"api.retries", "Number of retries for failed API requests"},
+	{"api.rate_limit", "Maximum API requests per minute, 0 for no limit"},
+	{"api.url", "Root of the REST API, e.g. a local mock-server"},
+	{"api.ws_url", "WebSocket API endpoint"},
+}
+
+// ProfileKeys lists the settings a station profile may hold. In the file
+// they live under profiles.<alias>, e.g. profiles.roof.station.
+var ProfileKeys = []Key{
+	{"station", "Station ID the alias refers to"},
+	{"token", "API token for the station, if it belongs to another account"},
+	{"units.temp", "Temperature unit for this station"},
+	{"units.wind", "Wind speed unit for this station"},
+	{"units.pressure", "Pressure unit for this station"},
+	{"units.precip", "Precipitation unit for this station"},
+	{"units.distance", "Distance unit for this station"},
+}
+
+// KeyBindings lists the live dashboard actions whose keys can be changed.
+// In the file they live under keys.<action>, e.g. keys.pause, as a
+// comma-separated list of keys such as "p,space".
+var KeyBindings = []Key{
+	{"quit", "Quit the dashboard"},
+	{"help", "Show or hide the list of keys"},
+	{"next_station", "Show the next station"},
+	{"prev_station", "Show the previous station"},
+	{"grid", "Show every station as a tile, or go back to tabs"},
+	{"tabs", "Go back from the tiles to the tabs"},
+	{"focus", "Move the focus to the next panel"},
+	{"forward", "Scroll the focused panel, or else the forecast, forward"},
+	{"back", "Scroll the focused panel, or else the forecast, back"},
+	{"chart", "Chart the next metric"},
+	{"temp_unit", "Switch to the next temperature unit"},
+	{"wind_unit", "Switch to the next wind speed unit"},
+	{"precip_unit", "Switch to the next precipitation unit"},
+	{"pause", "Pause or resume updates"},
+	{"reconnect", "Reconnect to the data source now"},
+	{"clear_events", "Clear the events of the station shown"},
+}
+
+// IsKnown reports whether key is a supported setting.
+func IsKnown(key string) bool {
+	for _, k := range Keys {
+		if k.Name == key {
+			return true
+		}
+	}
+	if name, ok := strings.CutPrefix(key, "groups."); ok && ValidAlias(name) {
+		return true
+	}
+	if action, ok := strings.CutPrefix(key, "keys."); ok {
+		for _, k := range KeyBindings {
+			if k.Name == action {
+				return true
+			}
+		}
+	}
+	if alias, rest, ok := splitProfileKey(key); ok && ValidAlias(alias) {
+		for _, k := range ProfileKeys {
+			if k.Name == rest {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ProfileKey returns the config key of setting for the profile alias.
+func ProfileKey(alias, setting string) string {
+	return "profiles." + alias + "." + setting
+}
+
+// GroupKey returns the config key of the station group name. Its value is a
+// comma-separated list of station IDs and profile aliases.
+func GroupKey(name string) string {
+	return "groups." + name
+}
+
+// KeyBindingKey returns the config key of the keys bound to a dashboard
+// action.
+func KeyBindingKey(action string) string {
+	return "keys." + action
+}
+
+func splitProfileKey(key string) (alias, setting string, ok bool) {
+	rest, ok := strings.CutPrefix(key, "profiles.")
+	if !ok {
+		return "", "", false
+	}
+	return strings.Cut(rest, ".")
+}
+
+// ValidAlias reports whether s can be used as a profile alias. Aliases must
+// not look like station IDs so the two can share the -s flag.
+func ValidAlias(s string) bool {
+	if s == "" {
+		return false
+	}
+	digits := true
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '-', r == '_':
+			digits = false
+		default:
+			return false
+		}
+	}
+	return !digits
+}
+
+// Config is a loaded configuration file.
+type Config struct {
+	path   string
+	format Format
+	values map[string]string
+}
+
+// DefaultDir returns the tempest-cli configuration directory, honoring
+// XDG_CONFIG_HOME.
+func DefaultDir() (string, error) {
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, "tempest-cli"), nil
+}
+
+// DefaultPath returns the configuration file to use: an existing
+// config.toml, config.yaml or config.yml in DefaultDir, or config.toml if
+// none exists yet.
+func DefaultPath() (string, error) {
+	dir, err := DefaultDir()
+	if err != nil {
+		return "", err
+	}
+	for _, name := range []string{"config.toml", "config.yaml", "config.yml"} {
+		p := filepath.Join(dir, name)
+		if _, err := os.Stat(p); err == nil {
+			return p, nil
+		}
+	}
+	return filepath.Join(dir, "config.toml"), nil
+}
+
+// formatForPath picks the file syntax from the extension.
+func formatForPath(path string) (Format, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".toml":
+		return TOML, nil
+	case ".yaml", ".yml":
+		return YAML, nil
+	}
+	return 0, fmt.Errorf("unsupported config file type %q, use .toml or .yaml", filepath.Ext(path))
+}
+
+// Load reads the configuration file at path. A missing file yields an empty
+// configuration that will be created by Save.
+func Load(path string) (*Config, error) {
+	format, err := formatForPath(path)
+	if err != nil {
+		return nil, err
+	}
+	c := &Config{path: path, format: format, values: map[string]string{}}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch format {
+	case TOML:
+		c.values, err = parseTOML(string(data))
+	case YAML:
+		c.values, err = parseYAML(string(data))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return c, nil
+}
+
+// Path returns the file the configuration was loaded from.
+func (c *Config) Path() string {
+	return c.path
+}
+
+// Get returns the value of key.
+func (c *Config) Get(key string) (string, bool) {
+	v, ok := c.values[key]
+	return v, ok
+}
+
+// Set assigns value to key.
+func (c *Config) Set(key, value string) {
+	c.values[key] = value
+}
+
+// Unset removes key.
+func (c *Config) Unset(key string) {
+	delete(c.values, key)
+}
+
+// Profiles returns the aliases of every configured profile, sorted.
+func (c *Config) Profiles() []string {
+	seen := map[string]bool{}
+	var aliases []string
+	for k := range c.values {
+		if alias, _, ok := splitProfileKey(k); ok && !seen[alias] {
+			seen[alias] = true
+			aliases = append(aliases, alias)
+		}
+	}
+	sort.Strings(aliases)
+	return aliases
+}
+
+// HasProfile reports whether a profile named alias exists.
+func (c *Config) HasProfile(alias string) bool {
+	prefix := "profiles." + alias + "."
+	for k := range c.values {
+		if strings.HasPrefix(k, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// RemoveProfile deletes every setting of the profile alias.
+func (c *Config) RemoveProfile(alias string) {
+	prefix := "profiles." + alias + "."
+	for k := range c.values {
+		if strings.HasPrefix(k, prefix) {
+			delete(c.values, k)
+		}
+	}
+}
+
+// Groups returns the names of every configured station group, sorted.
+func (c *Config) Groups() []string {
+	var names []string
+	for k := range c.values {
+		if name, ok := strings.CutPrefix(k, "groups."); ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Group returns the members of the station group name.
+func (c *Config) Group(name string) ([]string, bool) {
+	v, ok := c.values[GroupKey(name)]
+	if !ok {
+		return nil, false
+	}
+	var members []string
+	for _, m := range strings.Split(v, ",") {
+		if m = strings.TrimSpace(m); m != "" {
+			members = append(members, m)
+		}
+	}
+	return members, true
+}
+
+// Keys returns every key that has a value, sorted.
+func (c *Config) Keys() []string {
+	keys := make([]string, 0, len(c.values))
+	for k := range c.values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Save writes the configuration back to its file, creating the directory if
+// needed. The file is only readable by the user since it may hold a token.
+func (c *Config) Save() error {
+	var out string
+	var err error
+	switch c.format {
+	case TOML:
+		out, err = encodeTOML(c.values)
+	case YAML:
+		out, err = encodeYAML(c.values)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", c.path, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(c.path, []byte(out), 0o600)
+}
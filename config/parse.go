@@ -0,0 +1,186 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// The configuration is a tree of scalar settings, held flat under dotted
+// keys: tables and mappings become key prefixes, values are kept as text.
+// Arrays are rejected.
+
+// parseTOML reads a TOML document into dotted keys.
+func parseTOML(src string) (map[string]string, error) {
+	var doc map[string]any
+	if _, err := toml.Decode(src, &doc); err != nil {
+		return nil, err
+	}
+	values := map[string]string{}
+	if err := flattenTOML("", doc, values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+func flattenTOML(prefix string, table map[string]any, values map[string]string) error {
+	for k, v := range table {
+		key := prefix + k
+		switch v := v.(type) {
+		case map[string]any:
+			if err := flattenTOML(key+".", v, values); err != nil {
+				return err
+			}
+		case string:
+			values[key] = v
+		case int64:
+			values[key] = strconv.FormatInt(v, 10)
+		case float64:
+			values[key] = strconv.FormatFloat(v, 'f', -1, 64)
+		case bool:
+			values[key] = strconv.FormatBool(v)
+		case time.Time:
+			values[key] = v.Format(time.RFC3339)
+		default:
+			return fmt.Errorf("%s: arrays are not supported", key)
+		}
+	}
+	return nil
+}
+
+// parseYAML reads a YAML document of nested mappings into dotted keys.
+// Scalars keep the text they were written with; null values are skipped.
+func parseYAML(src string) (map[string]string, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(src), &doc); err != nil {
+		return nil, err
+	}
+	values := map[string]string{}
+	if len(doc.Content) == 0 {
+		return values, nil
+	}
+	root := doc.Content[0]
+	if root.Kind == yaml.ScalarNode && root.Tag == "!!null" {
+		return values, nil
+	}
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("line %d: expected a mapping of settings", root.Line)
+	}
+	if err := flattenYAML("", root, values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+func flattenYAML(prefix string, m *yaml.Node, values map[string]string) error {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		k, v := m.Content[i], m.Content[i+1]
+		if k.Kind != yaml.ScalarNode || k.Value == "" {
+			return fmt.Errorf("line %d: invalid key", k.Line)
+		}
+		key := prefix + k.Value
+		if v.Kind == yaml.AliasNode {
+			v = v.Alias
+		}
+		switch {
+		case v.Kind == yaml.MappingNode:
+			if err := flattenYAML(key+".", v, values); err != nil {
+				return err
+			}
+		case v.Kind == yaml.ScalarNode && v.Tag == "!!null":
+		case v.Kind == yaml.ScalarNode:
+			values[key] = v.Value
+		default:
+			return fmt.Errorf("line %d: %s: lists are not supported", v.Line, key)
+		}
+	}
+	return nil
+}
+
+// typed returns v as the TOML or YAML type it reads as: a boolean, an
+// integer, a float or else a string, so numbers and booleans are written
+// bare. Numbers that would not be written back the same, such as "007",
+// stay strings.
+func typed(v string) any {
+	if v == "true" || v == "false" {
+		return v == "true"
+	}
+	if n, err := strconv.ParseInt(v, 10, 64); err == nil && strconv.FormatInt(n, 10) == v {
+		return n
+	}
+	if f, err := strconv.ParseFloat(v, 64); err == nil && strconv.FormatFloat(f, 'f', -1, 64) == v {
+		return f
+	}
+	return v
+}
+
+// nest turns dotted keys back into nested maps.
+func nest(values map[string]string) (map[string]any, error) {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	// Sorted, a key comes before any key under it.
+	sort.Strings(keys)
+
+	root := map[string]any{}
+	for _, k := range keys {
+		parts := strings.Split(k, ".")
+		m := root
+		for i, p := range parts[:len(parts)-1] {
+			child, ok := m[p].(map[string]any)
+			if !ok {
+				if _, isValue := m[p]; isValue {
+					return nil, fmt.Errorf("key %q conflicts with %q", k, strings.Join(parts[:i+1], "."))
+				}
+				child = map[string]any{}
+				m[p] = child
+			}
+			m = child
+		}
+		m[parts[len(parts)-1]] = typed(values[k])
+	}
+	return root, nil
+}
+
+// encodeTOML writes top-level keys first, then one table per prefix.
+func encodeTOML(values map[string]string) (string, error) {
+	tree, err := nest(values)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	enc := toml.NewEncoder(&buf)
+	enc.Indent = ""
+	if err := enc.Encode(tree); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// encodeYAML writes nested mappings, two spaces per level.
+func encodeYAML(values map[string]string) (string, error) {
+	tree, err := nest(values)
+	if err != nil {
+		return "", err
+	}
+	if len(tree) == 0 {
+		return "", nil
+	}
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(tree); err != nil {
+		return "", err
+	}
+	if err := enc.Close(); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
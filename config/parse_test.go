@@ -0,0 +1,152 @@
+package config
+
+import (
+	"maps"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		toml string
+		yaml string
+		want map[string]string
+	}{
+		{
+			name: "scalars",
+			toml: "token = \"abc\"\nstation = 1000\nratio = 1.5\nenabled = true\n",
+			yaml: "token: abc\nstation: 1000\nratio: 1.5\nenabled: true\n",
+			want: map[string]string{"token": "abc", "station": "1000", "ratio": "1.5", "enabled": "true"},
+		},
+		{
+			name: "nesting",
+			toml: "[units]\ntemp = \"f\"\n\n[profiles.roof]\nstation = 12\n[profiles.roof.units]\nwind = \"mph\"\n",
+			yaml: "units:\n  temp: f\nprofiles:\n  roof:\n    station: 12\n    units:\n      wind: mph\n",
+			want: map[string]string{"units.temp": "f", "profiles.roof.station": "12", "profiles.roof.units.wind": "mph"},
+		},
+		{
+			name: "dotted keys",
+			toml: "units.temp = \"c\"\n",
+			yaml: "units:\n  temp: c\n",
+			want: map[string]string{"units.temp": "c"},
+		},
+		{
+			name: "comments",
+			toml: "# settings\ntoken = \"abc\" # trailing\n[api] # table\nretries = 5\n",
+			yaml: "# settings\ntoken: abc # trailing\napi: # mapping\n  retries: 5\n",
+			want: map[string]string{"token": "abc", "api.retries": "5"},
+		},
+		{
+			name: "quoting",
+			toml: "a = \"x # not a comment\"\nb = 'single \\n'\nc = \"tab\\there\"\nd = \"007\"\ne = \"true\"\n",
+			yaml: "a: \"x # not a comment\"\nb: 'single \\n'\nc: \"tab\\there\"\nd: \"007\"\ne: \"true\"\n",
+			want: map[string]string{"a": "x # not a comment", "b": `single \n`, "c": "tab\there", "d": "007", "e": "true"},
+		},
+		{
+			name: "empty",
+			toml: "# nothing\n",
+			yaml: "---\n",
+			want: map[string]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name+"/toml", func(t *testing.T) {
+			got, err := parseTOML(tt.toml)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !maps.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+		t.Run(tt.name+"/yaml", func(t *testing.T) {
+			got, err := parseYAML(tt.yaml)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !maps.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		parse func(string) (map[string]string, error)
+		src   string
+		want  string
+	}{
+		{"toml array", parseTOML, "stations = [1, 2]\n", "arrays are not supported"},
+		{"toml syntax", parseTOML, "token = \n", ""},
+		{"toml unterminated string", parseTOML, "token = \"abc\n", ""},
+		{"yaml list", parseYAML, "stations:\n  - 1\n  - 2\n", "lists are not supported"},
+		{"yaml flow list", parseYAML, "stations: [1, 2]\n", "lists are not supported"},
+		{"yaml not a mapping", parseYAML, "just text\n", "expected a mapping"},
+		{"yaml tab indent", parseYAML, "units:\n\ttemp: f\n", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.parse(tt.src)
+			if err == nil {
+				t.Fatal("no error")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error %q does not mention %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncodeRoundTrip(t *testing.T) {
+	values := map[string]string{
+		"token":                 "abc#def",
+		"station":               "1000",
+		"units.temp":            "f",
+		"cache.enabled":         "true",
+		"cache.max_age":         "5m",
+		"keys.quit":             "q,ctrl+c",
+		"profiles.roof.station": "12",
+		"groups.home":           "roof, 1000",
+		"quoted":                `say "hi": now`,
+		"zeros":                 "007",
+		"decimal":               "1.50",
+		"yes":                   "yes",
+		"empty":                 "",
+	}
+	codecs := []struct {
+		name   string
+		encode func(map[string]string) (string, error)
+		parse  func(string) (map[string]string, error)
+	}{
+		{"toml", encodeTOML, parseTOML},
+		{"yaml", encodeYAML, parseYAML},
+	}
+	for _, c := range codecs {
+		t.Run(c.name, func(t *testing.T) {
+			out, err := c.encode(values)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := c.parse(out)
+			if err != nil {
+				t.Fatalf("parsing\n%s: %v", out, err)
+			}
+			if !maps.Equal(got, values) {
+				t.Errorf("round trip through\n%s gave %v, want %v", out, got, values)
+			}
+		})
+	}
+}
+
+func TestEncodeConflict(t *testing.T) {
+	values := map[string]string{"units": "metric", "units.temp": "f"}
+	if _, err := encodeTOML(values); err == nil {
+		t.Error("encodeTOML: no error for a key that is both a value and a table")
+	}
+	if _, err := encodeYAML(values); err == nil {
+		t.Error("encodeYAML: no error for a key that is both a value and a mapping")
+	}
+}
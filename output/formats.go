@@ -0,0 +1,144 @@
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+func init() {
+	Register("json", noArg("json", jsonFormat{}))
+	Register("yaml", noArg("yaml", yamlFormat{}))
+	Register("csv", noArg("csv", delimitedFormat{comma: ','}))
+	Register("tsv", noArg("tsv", delimitedFormat{comma: '\t'}))
+	Register("table", noArg("table", tableFormat{}))
+	Register("template", newTemplateFormat)
+}
+
+// noArg wraps a formatter that takes no argument.
+func noArg(name string, f Formatter) Factory {
+	return func(arg string) (Formatter, error) {
+		if arg != "" {
+			return nil, fmt.Errorf("output format %s takes no argument", name)
+		}
+		return f, nil
+	}
+}
+
+type jsonFormat struct{}
+
+func (jsonFormat) Format(w io.Writer, r Result) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r.Value)
+}
+
+type yamlFormat struct{}
+
+func (yamlFormat) Format(w io.Writer, r Result) error {
+	tree, err := toTree(r.Value)
+	if err != nil {
+		return err
+	}
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(yamlNode(tree)); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// delimitedFormat writes CSV, or TSV when comma is a tab.
+type delimitedFormat struct {
+	comma rune
+}
+
+func (f delimitedFormat) Format(w io.Writer, r Result) error {
+	t, err := tabulate(r.rows())
+	if err != nil {
+		return err
+	}
+	cw := csv.NewWriter(w)
+	cw.Comma = f.comma
+	if err := cw.Write(t.Columns); err != nil {
+		return err
+	}
+	if err := cw.WriteAll(t.Rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+type tableFormat struct{}
+
+func (tableFormat) Format(w io.Writer, r Result) error {
+	t, err := tabulate(r.rows())
+	if err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	header := make([]string, len(t.Columns))
+	for i, c := range t.Columns {
+		header[i] = strings.ToUpper(c)
+	}
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+	for _, row := range t.Rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+// templateFormat executes a text/template once per row, like
+// "docker ps --format". Rows of a Table are passed as a map of column to
+// value.
+type templateFormat struct {
+	tmpl *template.Template
+}
+
+func newTemplateFormat(arg string) (Formatter, error) {
+	if arg == "" {
+		return nil, fmt.Errorf("output format template needs a template, e.g. -o template='{{.AirTemperature}}'")
+	}
+	if !strings.HasSuffix(arg, "\n") {
+		arg += "\n"
+	}
+	tmpl, err := template.New("output").Parse(arg)
+	if err != nil {
+		return nil, fmt.Errorf("parsing output template: %w", err)
+	}
+	return templateFormat{tmpl}, nil
+}
+
+func (f templateFormat) Format(w io.Writer, r Result) error {
+	rows := r.rows()
+	if t, ok := rows.(Table); ok {
+		for _, row := range t.Rows {
+			m := make(map[string]string, len(t.Columns))
+			for i, c := range t.Columns {
+				m[c] = row[i]
+			}
+			if err := f.tmpl.Execute(w, m); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	rv := reflect.ValueOf(rows)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return f.tmpl.Execute(w, rows)
+	}
+	for i := 0; i < rv.Len(); i++ {
+		if err := f.tmpl.Execute(w, rv.Index(i).Interface()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
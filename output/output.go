@@ -0,0 +1,80 @@
+// Package output renders command results in machine-readable formats.
+//
+// Formats are looked up by name in a registry, so every command supports the
+// same set. A format is selected with a spec such as "json", "CSV" or
+// "template={{.AirTemperature}}": the name is case-insensitive and anything
+// after the first "=" is passed to the format as its argument.
+package output
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Result is what a command hands to a formatter.
+type Result struct {
+	// Value is the complete result, rendered by document formats such as
+	// json and yaml.
+	Value any
+	// Rows is the tabular view of the result, rendered by csv, tsv, table
+	// and template: a slice whose elements become rows, or a Table. When nil,
+	// Value is used.
+	Rows any
+}
+
+func (r Result) rows() any {
+	if r.Rows != nil {
+		return r.Rows
+	}
+	return r.Value
+}
+
+// Table is a pre-built tabular view, for results whose columns are not simply
+// the fields of a struct.
+type Table struct {
+	Columns []string
+	Rows    [][]string
+}
+
+// Formatter writes a result in one output format.
+type Formatter interface {
+	Format(w io.Writer, r Result) error
+}
+
+// Factory creates a formatter from the argument given after "=" in the
+// format spec, which is empty when there is none.
+type Factory func(arg string) (Formatter, error)
+
+var registry = map[string]Factory{}
+
+// Register makes a format available under name. It panics if the name is
+// already taken.
+func Register(name string, f Factory) {
+	name = strings.ToLower(name)
+	if _, dup := registry[name]; dup {
+		panic("output: format " + name + " registered twice")
+	}
+	registry[name] = f
+}
+
+// Names returns the registered format names, sorted.
+func Names() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// New returns the formatter for spec.
+func New(spec string) (Formatter, error) {
+	name, arg, _ := strings.Cut(spec, "=")
+	f, ok := registry[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return nil, fmt.Errorf("unknown output format %q, valid formats are: %s", name, strings.Join(Names(), ", "))
+	}
+	return f(arg)
+}
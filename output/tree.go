@@ -0,0 +1,184 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// field is a key/value pair of an object, kept in the order the fields were
+// encoded so output follows the struct definition.
+type field struct {
+	key   string
+	value any
+}
+
+// object is a decoded JSON object. Other values decode to []any, string,
+// json.Number, bool or nil.
+type object []field
+
+// toTree converts v into the generic form shared by the document and tabular
+// formats, going through its JSON encoding so json tags decide the names.
+func toTree(v any) (any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return decodeValue(dec)
+}
+
+func decodeValue(dec *json.Decoder) (any, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch tok {
+	case json.Delim('{'):
+		var obj object
+		for dec.More() {
+			keyTok, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			v, err := decodeValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			obj = append(obj, field{keyTok.(string), v})
+		}
+		_, err := dec.Token() // closing brace
+		return obj, err
+	case json.Delim('['):
+		list := []any{}
+		for dec.More() {
+			v, err := decodeValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		}
+		_, err := dec.Token() // closing bracket
+		return list, err
+	}
+	return tok, nil
+}
+
+// flatten turns a tree into a single row. Nested objects become dotted
+// columns such as "station_meta.elevation"; lists are kept as compact JSON.
+func flatten(prefix string, v any, row *object) {
+	obj, ok := v.(object)
+	if !ok {
+		*row = append(*row, field{prefix, v})
+		return
+	}
+	for _, f := range obj {
+		key := f.key
+		if prefix != "" {
+			key = prefix + "." + f.key
+		}
+		flatten(key, f.value, row)
+	}
+}
+
+// cell formats a flattened value for a table cell.
+func cell(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return fmt.Sprint(v)
+	}
+	var buf bytes.Buffer
+	writeCompactJSON(&buf, v)
+	return buf.String()
+}
+
+// writeCompactJSON encodes a tree as single-line JSON, keeping field order.
+func writeCompactJSON(buf *bytes.Buffer, v any) {
+	switch v := v.(type) {
+	case object:
+		buf.WriteByte('{')
+		for i, f := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			key, _ := json.Marshal(f.key)
+			buf.Write(key)
+			buf.WriteByte(':')
+			writeCompactJSON(buf, f.value)
+		}
+		buf.WriteByte('}')
+	case []any:
+		buf.WriteByte('[')
+		for i, e := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeCompactJSON(buf, e)
+		}
+		buf.WriteByte(']')
+	default:
+		data, _ := json.Marshal(v)
+		buf.Write(data)
+	}
+}
+
+// tabulate builds a Table from rows: a Table is returned as is, a slice or
+// array yields one row per element and anything else a single row. Columns
+// are the union of every row's flattened fields, in first-seen order.
+func tabulate(rows any) (Table, error) {
+	if t, ok := rows.(Table); ok {
+		return t, nil
+	}
+	var items []any
+	rv := reflect.ValueOf(rows)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			items = append(items, rv.Index(i).Interface())
+		}
+	default:
+		items = []any{rows}
+	}
+
+	var t Table
+	index := map[string]int{}
+	var flat []map[string]any
+	for _, item := range items {
+		tree, err := toTree(item)
+		if err != nil {
+			return Table{}, err
+		}
+		var row object
+		flatten("", tree, &row)
+		values := map[string]any{}
+		for _, f := range row {
+			key := f.key
+			if key == "" {
+				key = "value"
+			}
+			if _, ok := index[key]; !ok {
+				index[key] = len(t.Columns)
+				t.Columns = append(t.Columns, key)
+			}
+			values[key] = f.value
+		}
+		flat = append(flat, values)
+	}
+	for _, values := range flat {
+		row := make([]string, len(t.Columns))
+		for i, col := range t.Columns {
+			row[i] = strings.ReplaceAll(cell(values[col]), "\n", " ")
+		}
+		t.Rows = append(t.Rows, row)
+	}
+	return t, nil
+}
@@ -0,0 +1,104 @@
+package output
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestYAMLFormat(t *testing.T) {
+	type inner struct {
+		Name  string   `json:"name"`
+		Tags  []string `json:"tags"`
+		Empty []int    `json:"empty"`
+	}
+	type doc struct {
+		Zebra   string         `json:"zebra"`
+		Alpha   int            `json:"alpha"`
+		Ratio   float64        `json:"ratio"`
+		On      bool           `json:"on"`
+		Missing *int           `json:"missing"`
+		Inner   inner          `json:"inner"`
+		Items   []inner        `json:"items"`
+		Extra   map[string]any `json:"extra"`
+	}
+	v := doc{
+		Zebra: "first",
+		Alpha: 2,
+		Ratio: 1.5,
+		On:    true,
+		Inner: inner{Name: "a: b", Tags: []string{"x"}, Empty: []int{}},
+		Items: []inner{{Name: "one"}, {Name: "two"}},
+		Extra: map[string]any{},
+	}
+
+	var b strings.Builder
+	if err := (yamlFormat{}).Format(&b, Result{Value: v}); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+
+	// Fields keep the struct's order rather than being sorted.
+	if strings.Index(out, "zebra:") > strings.Index(out, "alpha:") {
+		t.Errorf("fields out of order:\n%s", out)
+	}
+	for _, want := range []string{"missing: null\n", "empty: []\n", "extra: {}\n", "- name: one\n"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output lacks %q:\n%s", want, out)
+		}
+	}
+
+	var got map[string]any
+	if err := yaml.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("output is not valid YAML: %v\n%s", err, out)
+	}
+	want := map[string]any{
+		"zebra":   "first",
+		"alpha":   2,
+		"ratio":   1.5,
+		"on":      true,
+		"missing": nil,
+		"inner":   map[string]any{"name": "a: b", "tags": []any{"x"}, "empty": []any{}},
+		"items": []any{
+			map[string]any{"name": "one", "tags": nil, "empty": nil},
+			map[string]any{"name": "two", "tags": nil, "empty": nil},
+		},
+		"extra": map[string]any{},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("decoded %v, want %v", got, want)
+	}
+}
+
+func TestYAMLStringQuoting(t *testing.T) {
+	// Each string must read back as the same string, not another type.
+	strs := []string{
+		"", "plain", "true", "no", "off", "null", "~", "42", "1.5", "1e3", "0x1F",
+		"-dash", "? question", "a: b", "#hash", "x # y", "[list]", "{map}", "&anchor",
+		"*alias", "!tag", "|block", ">fold", "'single'", `"double"`, "%percent",
+		"@at", "`tick`", " padded ", "multi\nline", "tab\there", `back\slash`,
+		"2026-10-16", "ünïcödé",
+	}
+	for _, s := range strs {
+		var b strings.Builder
+		if err := (yamlFormat{}).Format(&b, Result{Value: map[string]string{"v": s}}); err != nil {
+			t.Fatal(err)
+		}
+		var got map[string]string
+		if err := yaml.Unmarshal([]byte(b.String()), &got); err != nil {
+			t.Errorf("%q: invalid YAML %q: %v", s, b.String(), err)
+			continue
+		}
+		if got["v"] != s {
+			t.Errorf("%q encoded as %q reads back as %q", s, b.String(), got["v"])
+		}
+
+		var typed map[string]any
+		yaml.Unmarshal([]byte(b.String()), &typed)
+		if _, ok := typed["v"].(string); !ok {
+			t.Errorf("%q encoded as %q reads back as %T", s, b.String(), typed["v"])
+		}
+	}
+}
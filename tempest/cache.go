@@ -0,0 +1,221 @@
+package tempest
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// CachePolicy sets how long cached responses stay fresh, per endpoint.
+type CachePolicy struct {
+	Station     time.Duration
+	Forecast    time.Duration
+	Observation time.Duration
+	// Other applies to endpoints without a dedicated TTL.
+	Other time.Duration
+	// MaxAge, when positive, overrides every per-endpoint TTL.
+	MaxAge time.Duration
+	// StaleOnError serves an expired entry when the API cannot be reached.
+	StaleOnError bool
+}
+
+// DefaultCachePolicy keeps station metadata for a day and weather data for a
+// few minutes.
+var DefaultCachePolicy = CachePolicy{
+	Station:      24 * time.Hour,
+	Forecast:     10 * time.Minute,
+	Observation:  time.Minute,
+	Other:        time.Minute,
+	StaleOnError: true,
+}
+
+// cacheRetention is how long cached responses are kept after they were
+// stored, to be served while fresh and as a fallback when the API cannot be
+// reached; older entries are pruned.
+const cacheRetention = 7 * 24 * time.Hour
+
+// retention returns how long entries are kept: cacheRetention, or longer
+// if the policy keeps responses fresh for longer.
+func (p CachePolicy) retention() time.Duration {
+	return max(cacheRetention, p.Station, p.Forecast, p.Observation, p.Other, p.MaxAge)
+}
+
+// cacheable reports whether a request should go through the cache.
+// Requests for a time range are not cached: ranges that end at the time of
+// the request, like history --last, make a new URL every time, and their
+// entries would only pile up.
+func cacheable(query url.Values) bool {
+	return !query.Has("time_start") && !query.Has("time_end")
+}
+
+// ttl returns how long a response for path stays fresh.
+func (p CachePolicy) ttl(path string) time.Duration {
+	if p.MaxAge > 0 {
+		return p.MaxAge
+	}
+	switch endpointName(path) {
+	case "stations":
+		return p.Station
+	case "better_forecast":
+		return p.Forecast
+	case "observations":
+		return p.Observation
+	}
+	return p.Other
+}
+
+// FileCache stores response bodies on disk, one file per request URL (which
+// covers endpoint, station and unit set), grouped in a directory per
+// endpoint.
+type FileCache struct {
+	dir string
+}
+
+// NewFileCache returns a cache rooted at dir. The directory is created on
+// first write.
+func NewFileCache(dir string) *FileCache {
+	return &FileCache{dir: dir}
+}
+
+// DefaultCacheDir returns the per-user cache directory for tempest-cli,
+// honoring XDG_CACHE_HOME.
+func DefaultCacheDir() (string, error) {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, "tempest-cli", "http"), nil
+}
+
+// Dir returns the directory the cache is stored in.
+func (c *FileCache) Dir() string {
+	return c.dir
+}
+
+// Get returns the cached body for key and when it was stored.
+func (c *FileCache) Get(key string) ([]byte, time.Time, bool) {
+	p := c.path(key)
+	info, err := os.Stat(p)
+	if err != nil {
+		return nil, time.Time{}, false
+	}
+	body, err := os.ReadFile(p)
+	if err != nil {
+		return nil, time.Time{}, false
+	}
+	return body, info.ModTime(), true
+}
+
+// Put stores body under key, replacing any previous entry.
+func (c *FileCache) Put(key string, body []byte) error {
+	p := c.path(key)
+	if err := os.MkdirAll(filepath.Dir(p), 0o700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(p), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(body); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), p)
+}
+
+// Prune removes the entries stored more than maxAge ago, and any temporary
+// files left behind by interrupted writes.
+func (c *FileCache) Prune(maxAge time.Duration) error {
+	cutoff := time.Now().Add(-maxAge)
+	err := filepath.WalkDir(c.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		if info.ModTime().Before(cutoff) {
+			if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return err
+			}
+		}
+		return nil
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// Clear removes every cached response.
+func (c *FileCache) Clear() error {
+	err := os.RemoveAll(c.dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (c *FileCache) path(key string) string {
+	return filepath.Join(c.dir, filepath.FromSlash(key)+".json")
+}
+
+// endpointName returns the first segment of an API path, such as "stations"
+// for /stations/123.
+func endpointName(path string) string {
+	path = strings.TrimPrefix(path, "/")
+	name, _, _ := strings.Cut(path, "/")
+	if name == "" {
+		return "root"
+	}
+	return name
+}
+
+// cacheKey identifies a response by endpoint, full request URL and the
+// token used, without storing the token itself. Keys have the form
+// "<endpoint>/<hash>".
+func (c *Client) cacheKey(path, u string) string {
+	sum := sha256.Sum256([]byte(u + "#" + c.token))
+	return endpointName(path) + "/" + hex.EncodeToString(sum[:12])
+}
+
+// ResponseInfo describes where the data of the last request made with a
+// context came from. Attach one with WithResponseInfo.
+type ResponseInfo struct {
+	// FromCache is set when the response was served from the cache.
+	FromCache bool
+	// Stale is set when an expired cache entry was served because the API
+	// request failed.
+	Stale bool
+	// Age is how old the cached entry was.
+	Age time.Duration
+	// Err is the request error that caused a stale entry to be served.
+	Err error
+}
+
+type responseInfoKey struct{}
+
+// WithResponseInfo returns a context that makes Client methods record cache
+// details into info.
+func WithResponseInfo(ctx context.Context, info *ResponseInfo) context.Context {
+	return context.WithValue(ctx, responseInfoKey{}, info)
+}
+
+func recordResponseInfo(ctx context.Context, info ResponseInfo) {
+	if p, ok := ctx.Value(responseInfoKey{}).(*ResponseInfo); ok {
+		*p = info
+	}
+}
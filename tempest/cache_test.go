@@ -0,0 +1,65 @@
+package tempest
+
+import (
+	"net/url"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestFileCachePrune(t *testing.T) {
+	fc := NewFileCache(t.TempDir())
+	for _, key := range []string{"stations/old", "stations/new", "observations/old"} {
+		if err := fc.Put(key, []byte(`{}`)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	old := time.Now().Add(-8 * 24 * time.Hour)
+	for _, key := range []string{"stations/old", "observations/old"} {
+		if err := os.Chtimes(fc.path(key), old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := fc.Prune(cacheRetention); err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]bool{"stations/old": false, "stations/new": true, "observations/old": false} {
+		if _, _, ok := fc.Get(key); ok != want {
+			t.Errorf("after Prune, Get(%q) found = %v, want %v", key, ok, want)
+		}
+	}
+}
+
+func TestFileCachePruneMissingDir(t *testing.T) {
+	if err := NewFileCache(t.TempDir() + "/missing").Prune(time.Hour); err != nil {
+		t.Errorf("Prune of a missing cache: %v", err)
+	}
+}
+
+func TestCacheable(t *testing.T) {
+	tests := []struct {
+		query url.Values
+		want  bool
+	}{
+		{url.Values{"station_id": {"1"}}, true},
+		{url.Values{"time_start": {"1"}, "time_end": {"2"}}, false},
+		{url.Values{"time_end": {"2"}}, false},
+	}
+	for _, tt := range tests {
+		if got := cacheable(tt.query); got != tt.want {
+			t.Errorf("cacheable(%v) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestCachePolicyRetention(t *testing.T) {
+	p := DefaultCachePolicy
+	if got := p.retention(); got != cacheRetention {
+		t.Errorf("retention = %v, want %v", got, cacheRetention)
+	}
+	p.MaxAge = 30 * 24 * time.Hour
+	if got := p.retention(); got != p.MaxAge {
+		t.Errorf("retention with a long MaxAge = %v, want %v", got, p.MaxAge)
+	}
+}
@@ -0,0 +1,394 @@
+// Package tempest is a client for the WeatherFlow Tempest REST and WebSocket
+// APIs.
+package tempest
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultBaseURL is the root of the Tempest REST API.
+	DefaultBaseURL = "https://swd.weatherflow.com/swd/rest"
+
+	// DefaultTimeout bounds a single request when no http.Client is supplied.
+	DefaultTimeout = 15 * time.Second
+
+	// DefaultUserAgent is sent with every request unless overridden.
+	DefaultUserAgent = "tempest-cli"
+)
+
+// Client talks to the Tempest REST API. The zero value is not usable; create
+// one with NewClient.
+type Client struct {
+	baseURL    string
+	token      string
+	userAgent  string
+	httpClient *http.Client
+	retry      RetryPolicy
+	limiter    *RateLimiter
+	logger     func(format string, args ...any)
+
+	cache       *FileCache
+	cachePolicy CachePolicy
+	pruneOnce   sync.Once
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithBaseURL points the client at a different API root, such as a local
+// stand-in server.
+func WithBaseURL(u string) Option {
+	return func(c *Client) {
+		c.baseURL = strings.TrimRight(u, "/")
+	}
+}
+
+// WithHTTPClient replaces the underlying http.Client. A nil hc stands for
+// http.DefaultClient.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = cmp.Or(hc, http.DefaultClient)
+	}
+}
+
+// WithTimeout sets the per-request timeout of the underlying http.Client.
+// The client is copied, so one passed to WithHTTPClient is left unchanged.
+func WithTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		hc := *cmp.Or(c.httpClient, http.DefaultClient)
+		hc.Timeout = d
+		c.httpClient = &hc
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request.
+func WithUserAgent(ua string) Option {
+	return func(c *Client) {
+		c.userAgent = ua
+	}
+}
+
+// WithRetry sets the policy used to retry failed requests. Pass a policy
+// with MaxRetries of 0 to disable retries.
+func WithRetry(p RetryPolicy) Option {
+	return func(c *Client) {
+		c.retry = p
+	}
+}
+
+// WithRateLimiter makes every request wait for a token from l. A single
+// limiter may be shared by several clients.
+func WithRateLimiter(l *RateLimiter) Option {
+	return func(c *Client) {
+		c.limiter = l
+	}
+}
+
+// WithCache stores successful responses in fc and serves them while they
+// are fresh according to policy.
+func WithCache(fc *FileCache, policy CachePolicy) Option {
+	return func(c *Client) {
+		c.cache = fc
+		c.cachePolicy = policy
+	}
+}
+
+// WithLogger sets a function that receives diagnostic messages, such as
+// notices about retried requests.
+func WithLogger(logf func(format string, args ...any)) Option {
+	return func(c *Client) {
+		c.logger = logf
+	}
+}
+
+// NewClient returns a Client authenticating with the given personal access
+// token.
+func NewClient(token string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    DefaultBaseURL,
+		token:      token,
+		userAgent:  DefaultUserAgent,
+		httpClient: &http.Client{Timeout: DefaultTimeout},
+		retry:      DefaultRetryPolicy,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// BaseURL returns the API root the client sends requests to.
+func (c *Client) BaseURL() string {
+	return c.baseURL
+}
+
+// ForecastUnitOptions selects the units better_forecast reports values in.
+// Empty fields leave the choice to the API.
+type ForecastUnitOptions struct {
+	Temp     string // c, f
+	Wind     string // mps, mph, kph, kts, bft, lfm
+	Pressure string // mb, inhg, mmhg, hpa
+	Precip   string // mm, cm, in
+	Distance string // km, mi
+}
+
+func (o ForecastUnitOptions) apply(q url.Values) {
+	set := func(key, val string) {
+		if val != "" {
+			q.Set(key, val)
+		}
+	}
+	set("units_temp", o.Temp)
+	set("units_wind", o.Wind)
+	set("units_pressure", o.Pressure)
+	set("units_precip", o.Precip)
+	set("units_distance", o.Distance)
+}
+
+// ListStations returns every station the token has access to.
+func (c *Client) ListStations(ctx context.Context) (*Station, error) {
+	var s Station
+	if err := c.get(ctx, "/stations", nil, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// GetStation returns the metadata and devices of a single station.
+func (c *Client) GetStation(ctx context.Context, stationID int) (*Station, error) {
+	var s Station
+	if err := c.get(ctx, "/stations/"+strconv.Itoa(stationID), nil, &s); err != nil {
+		return nil, err
+	}
+	if len(s.Stations) == 0 {
+		return nil, fmt.Errorf("station %d: %w", stationID, ErrNotFound)
+	}
+	return &s, nil
+}
+
+// GetStationObservation returns the latest observation of a station.
+func (c *Client) GetStationObservation(ctx context.Context, stationID int) (*Observation, error) {
+	var o Observation
+	if err := c.get(ctx, "/observations/station/"+strconv.Itoa(stationID), nil, &o); err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
+
+// MaxObservationRange is the longest time range requested from the device
+// observations endpoint in one call. Longer ranges are split into chunks so
+// the API keeps returning one-minute resolution data.
+const MaxObservationRange = 24 * time.Hour
+
+// GetDeviceObservations returns the observations a device recorded between
+// start and end. Ranges longer than MaxObservationRange are fetched in
+// chunks and joined in time order.
+func (c *Client) GetDeviceObservations(ctx context.Context, deviceID int, start, end time.Time) (*DeviceObservations, error) {
+	if !end.After(start) {
+		return nil, fmt.Errorf("invalid time range: end %s is not after start %s",
+			end.Format(time.RFC3339), start.Format(time.RFC3339))
+	}
+
+	result := &DeviceObservations{DeviceID: deviceID}
+	for chunkStart := start; chunkStart.Before(end); chunkStart = chunkStart.Add(MaxObservationRange) {
+		chunkEnd := chunkStart.Add(MaxObservationRange)
+		if chunkEnd.After(end) {
+			chunkEnd = end
+		}
+
+		q := url.Values{}
+		q.Set("time_start", strconv.FormatInt(chunkStart.Unix(), 10))
+		q.Set("time_end", strconv.FormatInt(chunkEnd.Unix(), 10))
+
+		var d DeviceObservations
+		if err := c.get(ctx, "/observations/device/"+strconv.Itoa(deviceID), q, &d); err != nil {
+			return nil, err
+		}
+		result.Status = d.Status
+		result.Type = d.Type
+		result.Source = d.Source
+		for _, row := range d.Obs {
+			// Chunks share their boundary second, so skip rows already seen.
+			if n := len(result.Obs); n > 0 && len(row) > 0 && len(result.Obs[n-1]) > 0 && row[0] <= result.Obs[n-1][0] {
+				continue
+			}
+			result.Obs = append(result.Obs, row)
+		}
+	}
+	return result, nil
+}
+
+// GetBetterForecast returns current conditions plus the hourly and daily
+// forecast for a station.
+func (c *Client) GetBetterForecast(ctx context.Context, stationID int, units ForecastUnitOptions) (*Forecast, error) {
+	q := url.Values{}
+	q.Set("station_id", strconv.Itoa(stationID))
+	units.apply(q)
+
+	var f Forecast
+	if err := c.get(ctx, "/better_forecast", q, &f); err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+// get issues a GET against path and decodes the JSON body into v. Fresh
+// cached responses are used when the client has a cache; otherwise the
+// request is made, retrying failed attempts according to the retry policy.
+func (c *Client) get(ctx context.Context, path string, query url.Values, v any) error {
+	u := c.baseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	var key string
+	useCache := c.cache != nil && cacheable(query)
+	if useCache {
+		key = c.cacheKey(path, u)
+		if body, stored, ok := c.cache.Get(key); ok {
+			age := time.Since(stored)
+			if age <= c.cachePolicy.ttl(path) && c.decode(path, 0, body, v) == nil {
+				c.logf("GET %s served from cache (%s old)", path, age.Round(time.Second))
+				recordResponseInfo(ctx, ResponseInfo{FromCache: true, Age: age})
+				return nil
+			}
+		}
+	}
+
+	body, status, err := c.fetch(ctx, u, path)
+	if err != nil {
+		if useCache && c.cachePolicy.StaleOnError && retryable(err) {
+			if stale, stored, ok := c.cache.Get(key); ok && c.decode(path, 0, stale, v) == nil {
+				age := time.Since(stored)
+				c.logf("GET %s failed (%v), serving stale cache entry (%s old)", path, err, age.Round(time.Second))
+				recordResponseInfo(ctx, ResponseInfo{FromCache: true, Stale: true, Age: age, Err: err})
+				return nil
+			}
+		}
+		return err
+	}
+
+	if err := c.decode(path, status, body, v); err != nil {
+		return err
+	}
+	if useCache {
+		if err := c.cache.Put(key, body); err != nil {
+			c.logf("caching %s: %v", path, err)
+		}
+		// Pruning once per client keeps the cache bounded without
+		// walking it on every request.
+		c.pruneOnce.Do(func() {
+			if err := c.cache.Prune(c.cachePolicy.retention()); err != nil {
+				c.logf("pruning cache: %v", err)
+			}
+		})
+	}
+	recordResponseInfo(ctx, ResponseInfo{})
+	return nil
+}
+
+// fetch performs the GET, retrying failed attempts according to the retry
+// policy, and returns the body of the first 2xx response.
+func (c *Client) fetch(ctx context.Context, u, path string) ([]byte, int, error) {
+	for attempt := 0; ; attempt++ {
+		if c.limiter != nil {
+			if err := c.limiter.Wait(ctx); err != nil {
+				return nil, 0, &RequestError{Op: "waiting for rate limiter", Path: path, Err: err}
+			}
+		}
+
+		body, status, err := c.do(ctx, u, path)
+		if err == nil {
+			return body, status, nil
+		}
+		delay, retry := c.retry.next(attempt, err)
+		if !retry || ctx.Err() != nil {
+			return nil, status, err
+		}
+		c.logf("GET %s failed (%v), retrying in %s (attempt %d/%d)", path, err, delay.Round(time.Millisecond), attempt+1, c.retry.MaxRetries)
+		if err := sleep(ctx, delay); err != nil {
+			return nil, status, &RequestError{Op: "waiting to retry", Path: path, Err: err}
+		}
+	}
+}
+
+// decode unmarshals body into v and checks the embedded status block.
+func (c *Client) decode(path string, status int, body []byte, v any) error {
+	if err := json.Unmarshal(body, v); err != nil {
+		return &DecodeError{Path: path, Err: err}
+	}
+	if sc, ok := v.(statusCarrier); ok {
+		if st := sc.apiStatus(); !st.OK() {
+			return &APIError{StatusCode: status, Status: st, Path: path, Body: body}
+		}
+	}
+	return nil
+}
+
+// do performs a single GET attempt and returns the body of a 2xx response.
+func (c *Client) do(ctx context.Context, u, path string) ([]byte, int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, 0, &RequestError{Op: "creating request", Path: path, Err: err}
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", c.userAgent)
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, 0, &RequestError{Op: "making request", Path: path, Err: err}
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, resp.StatusCode, &RequestError{Op: "reading response", Path: path, Err: err}
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		apiErr := &APIError{
+			StatusCode: resp.StatusCode,
+			Path:       path,
+			Body:       body,
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+		}
+		var envelope struct {
+			Status Status `json:"status"`
+		}
+		if json.Unmarshal(body, &envelope) == nil {
+			apiErr.Status = envelope.Status
+		}
+		return nil, resp.StatusCode, apiErr
+	}
+	return body, resp.StatusCode, nil
+}
+
+func (c *Client) logf(format string, args ...any) {
+	if c.logger != nil {
+		c.logger(format, args...)
+	}
+}
+
+// ParseStationID converts a station ID given on the command line into the
+// integer form the API expects.
+func ParseStationID(s string) (int, error) {
+	id, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid station ID %q", s)
+	}
+	return id, nil
+}
@@ -0,0 +1,390 @@
+package tempest_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"tempest-cli/tempest"
+	"tempest-cli/tempest/tempesttest"
+)
+
+// noRetry keeps tests that expect a failure from retrying it.
+var noRetry = tempest.WithRetry(tempest.RetryPolicy{})
+
+// flakyServer serves the mock API through fail, which answers a request
+// itself when it returns true. It counts the requests made.
+type flakyServer struct {
+	URL      string
+	requests atomic.Int32
+}
+
+func newFlakyServer(t *testing.T, fx tempesttest.Fixtures, fail func(w http.ResponseWriter, n int) bool) *flakyServer {
+	t.Helper()
+	fs := &flakyServer{}
+	h := tempesttest.NewHandler(fx)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(fs.requests.Add(1))
+		if fail != nil && fail(w, n) {
+			return
+		}
+		h.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	fs.URL = srv.URL + tempesttest.RESTPath
+	return fs
+}
+
+// failWith answers the first n requests with status and the given headers.
+func failWith(n, status int, header ...string) func(http.ResponseWriter, int) bool {
+	return func(w http.ResponseWriter, req int) bool {
+		if req > n {
+			return false
+		}
+		for i := 0; i+1 < len(header); i += 2 {
+			w.Header().Set(header[i], header[i+1])
+		}
+		w.WriteHeader(status)
+		return true
+	}
+}
+
+func TestWithTimeoutNilHTTPClient(t *testing.T) {
+	srv := tempesttest.NewServer(tempesttest.Fixtures{})
+	defer srv.Close()
+
+	tests := []struct {
+		name string
+		opts []tempest.Option
+	}{
+		{"timeout after nil client", []tempest.Option{tempest.WithHTTPClient(nil), tempest.WithTimeout(time.Second)}},
+		{"nil client after timeout", []tempest.Option{tempest.WithTimeout(time.Second), tempest.WithHTTPClient(nil)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := tempest.NewClient("token", append(tt.opts, tempest.WithBaseURL(srv.URL))...)
+			if _, err := c.ListStations(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+	if http.DefaultClient.Timeout != 0 {
+		t.Errorf("WithTimeout changed http.DefaultClient: Timeout = %v", http.DefaultClient.Timeout)
+	}
+}
+
+func TestClientErrors(t *testing.T) {
+	live := tempesttest.NewServer(tempesttest.Fixtures{}, tempesttest.WithToken("secret"))
+	defer live.Close()
+	badBody := tempesttest.NewServer(tempesttest.Fixtures{
+		Stations: []byte(`{"status":{"status_code":401,"status_message":"UNAUTHORIZED"}}`),
+	})
+	defer badBody.Close()
+	notJSON := tempesttest.NewServer(tempesttest.Fixtures{Stations: []byte(`<html>`)})
+	defer notJSON.Close()
+	closed := tempesttest.NewServer(tempesttest.Fixtures{})
+	closed.Close()
+
+	tests := []struct {
+		name    string
+		url     string
+		token   string
+		call    func(*tempest.Client) error
+		kind    error // sentinel matched with errors.Is, if any
+		errType any   // pointer to the error type matched with errors.As
+	}{
+		{
+			name: "bad token", url: live.URL, token: "wrong",
+			call:    func(c *tempest.Client) error { _, err := c.ListStations(context.Background()); return err },
+			kind:    tempest.ErrUnauthorized,
+			errType: new(*tempest.APIError),
+		},
+		{
+			name: "unknown station", url: live.URL, token: "secret",
+			call:    func(c *tempest.Client) error { _, err := c.GetStation(context.Background(), 999); return err },
+			kind:    tempest.ErrNotFound,
+			errType: new(*tempest.APIError),
+		},
+		{
+			name: "error in the body of a 200", url: badBody.URL, token: "secret",
+			call:    func(c *tempest.Client) error { _, err := c.ListStations(context.Background()); return err },
+			kind:    tempest.ErrUnauthorized,
+			errType: new(*tempest.APIError),
+		},
+		{
+			name: "body is not JSON", url: notJSON.URL, token: "secret",
+			call:    func(c *tempest.Client) error { _, err := c.ListStations(context.Background()); return err },
+			errType: new(*tempest.DecodeError),
+		},
+		{
+			name: "server unreachable", url: closed.URL, token: "secret",
+			call:    func(c *tempest.Client) error { _, err := c.ListStations(context.Background()); return err },
+			errType: new(*tempest.RequestError),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := tempest.NewClient(tt.token, tempest.WithBaseURL(tt.url), noRetry)
+			err := tt.call(c)
+			if err == nil {
+				t.Fatal("no error")
+			}
+			if tt.kind != nil && !errors.Is(err, tt.kind) {
+				t.Errorf("errors.Is(%v, %v) = false", err, tt.kind)
+			}
+			if !errors.As(err, tt.errType) {
+				t.Errorf("error %v (%T) is not a %T", err, err, tt.errType)
+			}
+		})
+	}
+
+	// The status of an HTTP error is kept on the APIError.
+	c := tempest.NewClient("wrong", tempest.WithBaseURL(live.URL), noRetry)
+	_, err := c.ListStations(context.Background())
+	var apiErr *tempest.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("got %v, want an APIError with status 401", err)
+	}
+}
+
+func TestClientRetry(t *testing.T) {
+	policy := tempest.RetryPolicy{MaxRetries: 3, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Second}
+	tests := []struct {
+		name         string
+		fail         func(http.ResponseWriter, int) bool
+		wantRequests int32
+		wantErr      error
+		minElapsed   time.Duration
+	}{
+		{name: "server errors then success", fail: failWith(2, http.StatusServiceUnavailable), wantRequests: 3},
+		{name: "retries exhausted", fail: failWith(10, http.StatusInternalServerError), wantRequests: 4, wantErr: tempest.ErrServer},
+		{name: "not retried", fail: failWith(10, http.StatusForbidden), wantRequests: 1},
+		{
+			name:         "Retry-After honoured",
+			fail:         failWith(1, http.StatusTooManyRequests, "Retry-After", "1"),
+			wantRequests: 2,
+			minElapsed:   time.Second,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newFlakyServer(t, tempesttest.Fixtures{}, tt.fail)
+			c := tempest.NewClient("token", tempest.WithBaseURL(srv.URL), tempest.WithRetry(policy))
+
+			start := time.Now()
+			_, err := c.GetStation(context.Background(), tempesttest.StationID)
+			elapsed := time.Since(start)
+
+			if n := srv.requests.Load(); n != tt.wantRequests {
+				t.Errorf("made %d requests, want %d", n, tt.wantRequests)
+			}
+			switch {
+			case tt.wantErr != nil && !errors.Is(err, tt.wantErr):
+				t.Errorf("got error %v, want %v", err, tt.wantErr)
+			case tt.wantErr == nil && tt.wantRequests > 1 && err != nil:
+				t.Errorf("got error %v after retrying", err)
+			}
+			if elapsed < tt.minElapsed {
+				t.Errorf("took %v, want at least %v", elapsed, tt.minElapsed)
+			}
+		})
+	}
+
+	// A Retry-After longer than MaxDelay is capped.
+	srv := newFlakyServer(t, tempesttest.Fixtures{}, failWith(1, http.StatusTooManyRequests, "Retry-After", "120"))
+	capped := tempest.RetryPolicy{MaxRetries: 1, BaseDelay: time.Millisecond, MaxDelay: 50 * time.Millisecond}
+	c := tempest.NewClient("token", tempest.WithBaseURL(srv.URL), tempest.WithRetry(capped))
+	start := time.Now()
+	if _, err := c.GetStation(context.Background(), tempesttest.StationID); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Retry-After of 120s not capped by MaxDelay: took %v", elapsed)
+	}
+}
+
+func TestClientCache(t *testing.T) {
+	var failing atomic.Bool
+	srv := newFlakyServer(t, tempesttest.Fixtures{}, func(w http.ResponseWriter, _ int) bool {
+		if !failing.Load() {
+			return false
+		}
+		w.WriteHeader(http.StatusBadGateway)
+		return true
+	})
+	cache := tempest.NewFileCache(t.TempDir())
+	get := func(c *tempest.Client) (tempest.ResponseInfo, error) {
+		var info tempest.ResponseInfo
+		_, err := c.GetStation(tempest.WithResponseInfo(context.Background(), &info), tempesttest.StationID)
+		return info, err
+	}
+
+	fresh := tempest.NewClient("token", tempest.WithBaseURL(srv.URL), noRetry,
+		tempest.WithCache(cache, tempest.DefaultCachePolicy))
+	if info, err := get(fresh); err != nil || info.FromCache {
+		t.Fatalf("first request: info %+v, error %v; want a response from the API", info, err)
+	}
+	if info, err := get(fresh); err != nil || !info.FromCache || info.Stale {
+		t.Fatalf("second request: info %+v, error %v; want a fresh cached response", info, err)
+	}
+	if n := srv.requests.Load(); n != 1 {
+		t.Errorf("made %d requests, want 1", n)
+	}
+
+	// With every entry expired, a failing API falls back to the stale entry
+	// only when the policy allows it.
+	failing.Store(true)
+	expired := tempest.CachePolicy{MaxAge: time.Nanosecond, StaleOnError: true}
+	stale := tempest.NewClient("token", tempest.WithBaseURL(srv.URL), noRetry, tempest.WithCache(cache, expired))
+	info, err := get(stale)
+	if err != nil || !info.Stale || !errors.Is(info.Err, tempest.ErrServer) {
+		t.Errorf("stale fallback: info %+v, error %v; want a stale entry and the server error", info, err)
+	}
+
+	expired.StaleOnError = false
+	strict := tempest.NewClient("token", tempest.WithBaseURL(srv.URL), noRetry, tempest.WithCache(cache, expired))
+	if _, err := get(strict); !errors.Is(err, tempest.ErrServer) {
+		t.Errorf("without StaleOnError: got %v, want the server error", err)
+	}
+}
+
+func TestGetDeviceObservationsChunks(t *testing.T) {
+	srv := newFlakyServer(t, tempesttest.Fixtures{}, nil)
+	c := tempest.NewClient("token", tempest.WithBaseURL(srv.URL))
+
+	// 50 hours take three chunks, each ending on the minute the next
+	// starts with.
+	start := time.Unix(1_700_000_000-1_700_000_000%60, 0)
+	end := start.Add(50 * time.Hour)
+	d, err := c.GetDeviceObservations(context.Background(), tempesttest.DeviceID, start, end)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := srv.requests.Load(); n != 3 {
+		t.Errorf("made %d requests, want 3", n)
+	}
+
+	want := int(end.Sub(start)/time.Minute) + 1
+	if len(d.Obs) != want {
+		t.Errorf("got %d rows, want %d, one per minute", len(d.Obs), want)
+	}
+	for i, row := range d.Obs {
+		if ts := int64(row[0]); ts != start.Unix()+int64(i)*60 {
+			t.Fatalf("row %d is at %d, want %d", i, ts, start.Unix()+int64(i)*60)
+		}
+	}
+	if d.DeviceID != tempesttest.DeviceID || d.Type != "obs_st" {
+		t.Errorf("got device %d type %q", d.DeviceID, d.Type)
+	}
+}
+
+// wsEnvelope is the part of a WebSocket message the tests look at.
+type wsEnvelope struct {
+	Type     string `json:"type"`
+	ID       string `json:"id"`
+	DeviceID int    `json:"device_id"`
+}
+
+func TestWSClientAckAndResubscribe(t *testing.T) {
+	srv := tempesttest.NewServer(tempesttest.Fixtures{}, tempesttest.WithIntervals(time.Hour, time.Hour))
+	defer srv.Close()
+
+	var mu sync.Mutex
+	var states []tempest.WSState
+	c := tempest.NewWSClient("token",
+		tempest.WithWSURL(srv.WSURL),
+		tempest.WithReconnectBackoff(tempest.RetryPolicy{BaseDelay: 10 * time.Millisecond, MaxDelay: 10 * time.Millisecond}),
+		tempest.WithStateHandler(func(s tempest.WSStateChange) {
+			mu.Lock()
+			states = append(states, s.State)
+			mu.Unlock()
+		}),
+	)
+	if err := c.Subscribe(tempesttest.DeviceID); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	msgs := make(chan wsEnvelope, 64)
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- c.Run(ctx, func(raw []byte) {
+			var m wsEnvelope
+			if json.Unmarshal(raw, &m) == nil {
+				msgs <- m
+			}
+		})
+	}()
+
+	// Every connection opens, acknowledges both subscriptions and streams
+	// the device's first obs_st and rapid_wind.
+	device := strconv.Itoa(tempesttest.DeviceID)
+	expectConnection := func(n int) {
+		t.Helper()
+		want := map[string]bool{
+			"connection_opened":               false,
+			"ack tempest-cli-obs-" + device:   false,
+			"ack tempest-cli-rapid-" + device: false,
+			"obs_st " + device:                false,
+			"rapid_wind " + device:            false,
+		}
+		timeout := time.After(5 * time.Second)
+		for missing := len(want); missing > 0; {
+			select {
+			case m := <-msgs:
+				key := m.Type
+				switch m.Type {
+				case "ack":
+					key += " " + m.ID
+				case "obs_st", "rapid_wind":
+					key += " " + strconv.Itoa(m.DeviceID)
+				}
+				if seen, ok := want[key]; ok && !seen {
+					want[key] = true
+					missing--
+				}
+			case <-timeout:
+				t.Fatalf("connection %d: messages not received: %v", n, want)
+			}
+		}
+	}
+	expectConnection(1)
+
+	c.Reconnect()
+	expectConnection(2)
+
+	cancel()
+	if err := <-runErr; !errors.Is(err, context.Canceled) {
+		t.Errorf("Run returned %v, want context.Canceled", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	var got []string
+	for _, s := range states {
+		got = append(got, s.String())
+	}
+	if want := "connecting connected reconnecting connecting connected closed"; strings.Join(got, " ") != want {
+		t.Errorf("states %q, want %q", strings.Join(got, " "), want)
+	}
+}
+
+func TestWSClientRejectedToken(t *testing.T) {
+	srv := tempesttest.NewServer(tempesttest.Fixtures{}, tempesttest.WithToken("secret"))
+	defer srv.Close()
+
+	c := tempest.NewWSClient("wrong", tempest.WithWSURL(srv.WSURL))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := c.Run(ctx, func([]byte) {}); !errors.Is(err, tempest.ErrUnauthorized) {
+		t.Errorf("Run returned %v, want ErrUnauthorized without retrying", err)
+	}
+}
@@ -0,0 +1,150 @@
+package tempest
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Sentinel errors for the failure kinds callers usually want to tell apart.
+// Use errors.Is against an error returned by a Client method.
+var (
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrNotFound     = errors.New("not found")
+	ErrRateLimited  = errors.New("rate limited")
+	ErrServer       = errors.New("server error")
+)
+
+// Status is the status block included in every Tempest response body.
+// A StatusCode of 0 means success.
+type Status struct {
+	StatusCode    int    `json:"status_code"`
+	StatusMessage string `json:"status_message"`
+}
+
+// OK reports whether the status block signals success.
+func (s Status) OK() bool {
+	return s.StatusCode == 0
+}
+
+// statusCarrier is implemented by response types that embed a status block.
+type statusCarrier interface {
+	apiStatus() Status
+}
+
+func (f *Forecast) apiStatus() Status           { return f.Status }
+func (o *Observation) apiStatus() Status        { return o.Status }
+func (s *Station) apiStatus() Status            { return s.Status }
+func (d *DeviceObservations) apiStatus() Status { return d.Status }
+
+// RequestError is returned when a request could not be sent or its response
+// could not be read.
+type RequestError struct {
+	Op   string
+	Path string
+	Err  error
+}
+
+func (e *RequestError) Error() string {
+	return fmt.Sprintf("%s %s: %v", e.Op, e.Path, e.Err)
+}
+
+func (e *RequestError) Unwrap() error {
+	return e.Err
+}
+
+// APIError is returned when the API answers with a non-2xx HTTP status or a
+// non-zero status code in the response body.
+type APIError struct {
+	// StatusCode is the HTTP status of the response.
+	StatusCode int
+	// Status is the decoded status block, if the body carried one.
+	Status Status
+	Path   string
+	Body   []byte
+	// RetryAfter is the delay requested by a Retry-After header, if any.
+	RetryAfter time.Duration
+}
+
+func (e *APIError) Error() string {
+	msg := e.Status.StatusMessage
+	if msg == "" {
+		msg = http.StatusText(e.StatusCode)
+	}
+	code := e.StatusCode
+	if code >= 200 && code <= 299 && e.Status.StatusCode != 0 {
+		code = e.Status.StatusCode
+	}
+
+	var hint string
+	switch e.Kind() {
+	case ErrUnauthorized:
+		hint = "check that your API token is valid and has access to this station"
+	case ErrNotFound:
+		hint = "check the station or device ID"
+	case ErrRateLimited:
+		hint = "too many requests, try again later"
+	case ErrServer:
+		hint = "the Tempest API is having problems, try again later"
+	default:
+		hint = "request failed"
+	}
+	return fmt.Sprintf("%s: %s (%d %s)", e.Path, hint, code, msg)
+}
+
+// Kind returns the sentinel error matching the failure, or nil when the
+// failure does not fit one of the known kinds.
+func (e *APIError) Kind() error {
+	if k := kindForCode(e.StatusCode); k != nil {
+		return k
+	}
+	if k := kindForCode(e.Status.StatusCode); k != nil {
+		return k
+	}
+	msg := strings.ToUpper(e.Status.StatusMessage)
+	switch {
+	case strings.Contains(msg, "UNAUTHORIZED"), strings.Contains(msg, "INVALID TOKEN"):
+		return ErrUnauthorized
+	case strings.Contains(msg, "NOT FOUND"):
+		return ErrNotFound
+	case strings.Contains(msg, "RATE LIMIT"), strings.Contains(msg, "TOO MANY"):
+		return ErrRateLimited
+	}
+	return nil
+}
+
+// Is lets errors.Is match an APIError against the sentinel errors.
+func (e *APIError) Is(target error) bool {
+	k := e.Kind()
+	return k != nil && k == target
+}
+
+func kindForCode(code int) error {
+	switch {
+	case code == http.StatusUnauthorized, code == http.StatusForbidden:
+		return ErrUnauthorized
+	case code == http.StatusNotFound:
+		return ErrNotFound
+	case code == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case code >= 500 && code <= 599:
+		return ErrServer
+	}
+	return nil
+}
+
+// DecodeError is returned when a response body is not the expected JSON.
+type DecodeError struct {
+	Path string
+	Err  error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("parsing response from %s: %v", e.Path, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
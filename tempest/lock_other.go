@@ -0,0 +1,18 @@
+//go:build !unix && !windows
+
+package tempest
+
+import (
+	"errors"
+	"os"
+)
+
+// lockFile reports that files cannot be locked here, so shared rate
+// limiters fall back to a bucket per process.
+func lockFile(f *os.File) error {
+	return errors.New("file locking not supported")
+}
+
+func unlockFile(f *os.File) error {
+	return nil
+}
@@ -0,0 +1,95 @@
+package tempest
+
+import "encoding/json"
+
+// Observation is the response body of the observations/station endpoint.
+type Observation struct {
+	Status    Status  `json:"status"`
+	Elevation float64 `json:"elevation"`
+	IsPublic  bool    `json:"is_public"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Obs       []struct {
+		AirDensity                       float64 `json:"air_density"`
+		AirTemperature                   float64 `json:"air_temperature"`
+		BarometricPressure               float64 `json:"barometric_pressure"`
+		Brightness                       int     `json:"brightness"`
+		DeltaT                           float64 `json:"delta_t"`
+		DewPoint                         float64 `json:"dew_point"`
+		FeelsLike                        float64 `json:"feels_like"`
+		HeatIndex                        float64 `json:"heat_index"`
+		LightningStrikeCount             int     `json:"lightning_strike_count"`
+		LightningStrikeCountLast1Hr      int     `json:"lightning_strike_count_last_1hr"`
+		LightningStrikeCountLast3Hr      int     `json:"lightning_strike_count_last_3hr"`
+		LightningStrikeLastDistance      float64 `json:"lightning_strike_last_distance"`
+		LightningStrikeLastEpoch         int     `json:"lightning_strike_last_epoch"`
+		Precip                           float64 `json:"precip"`
+		PrecipAccumLast1Hr               float64 `json:"precip_accum_last_1hr"`
+		PrecipAccumLocalDay              float64 `json:"precip_accum_local_day"`
+		PrecipAccumLocalDayFinal         float64 `json:"precip_accum_local_day_final"`
+		PrecipAccumLocalYesterday        float64 `json:"precip_accum_local_yesterday"`
+		PrecipAccumLocalYesterdayFinal   float64 `json:"precip_accum_local_yesterday_final"`
+		PrecipAnalysisTypeYesterday      int     `json:"precip_analysis_type_yesterday"`
+		PrecipMinutesLocalDay            int     `json:"precip_minutes_local_day"`
+		PrecipMinutesLocalYesterday      int     `json:"precip_minutes_local_yesterday"`
+		PrecipMinutesLocalYesterdayFinal int     `json:"precip_minutes_local_yesterday_final"`
+		PressureTrend                    string  `json:"pressure_trend"`
+		RelativeHumidity                 int     `json:"relative_humidity"`
+		SeaLevelPressure                 float64 `json:"sea_level_pressure"`
+		SolarRadiation                   int     `json:"solar_radiation"`
+		StationPressure                  float64 `json:"station_pressure"`
+		Timestamp                        int     `json:"timestamp"`
+		Uv                               float64 `json:"uv"`
+		WetBulbGlobeTemperature          float64 `json:"wet_bulb_globe_temperature"`
+		WetBulbTemperature               float64 `json:"wet_bulb_temperature"`
+		WindAvg                          float64 `json:"wind_avg"`
+		WindChill                        float64 `json:"wind_chill"`
+		WindDirection                    int     `json:"wind_direction"`
+		WindGust                         float64 `json:"wind_gust"`
+		WindLull                         float64 `json:"wind_lull"`
+	} `json:"obs"`
+	OutdoorKeys  []string `json:"outdoor_keys"`
+	PublicName   string   `json:"public_name"`
+	StationID    int      `json:"station_id"`
+	StationName  string   `json:"station_name"`
+	StationUnits struct {
+		UnitsDirection string `json:"units_direction"`
+		UnitsDistance  string `json:"units_distance"`
+		UnitsOther     string `json:"units_other"`
+		UnitsPrecip    string `json:"units_precip"`
+		UnitsPressure  string `json:"units_pressure"`
+		UnitsTemp      string `json:"units_temp"`
+		UnitsWind      string `json:"units_wind"`
+	} `json:"station_units"`
+	Timezone string `json:"timezone"`
+}
+
+// DeviceObservations is the response body of the observations/device
+// endpoint. Each row of Obs is an obs_st array as sent over the WebSocket.
+type DeviceObservations struct {
+	Status   Status   `json:"status"`
+	DeviceID int      `json:"device_id"`
+	Type     string   `json:"type"`
+	Source   string   `json:"source"`
+	Obs      []ObsRow `json:"obs"`
+}
+
+// ObsRow is a single observation array. Values the device did not report
+// are sent as null and decoded as 0.
+type ObsRow []float64
+
+// UnmarshalJSON decodes an array of numbers that may contain nulls.
+func (r *ObsRow) UnmarshalJSON(data []byte) error {
+	var raw []*float64
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	row := make(ObsRow, len(raw))
+	for i, v := range raw {
+		if v != nil {
+			row[i] = *v
+		}
+	}
+	*r = row
+	return nil
+}
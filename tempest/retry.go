@@ -0,0 +1,259 @@
+package tempest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RetryPolicy controls how failed GET requests are retried. Only transient
+// failures are retried: network errors, 429 Too Many Requests and 5xx
+// responses.
+type RetryPolicy struct {
+	// MaxRetries is the number of retries after the first attempt.
+	MaxRetries int
+	// BaseDelay is the backoff before the first retry; it doubles with
+	// every further attempt.
+	BaseDelay time.Duration
+	// MaxDelay caps the backoff and any Retry-After the server asks for.
+	MaxDelay time.Duration
+}
+
+// DefaultRetryPolicy is used by clients created without WithRetry.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries: 3,
+	BaseDelay:  500 * time.Millisecond,
+	MaxDelay:   30 * time.Second,
+}
+
+// next reports whether attempt (zero based) should be retried after err and
+// how long to wait first.
+func (p RetryPolicy) next(attempt int, err error) (time.Duration, bool) {
+	if attempt >= p.MaxRetries || !retryable(err) {
+		return 0, false
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+		return p.clamp(apiErr.RetryAfter), true
+	}
+	return p.backoff(attempt), true
+}
+
+// backoff returns the jittered exponential delay for attempt: a random
+// duration between half and all of BaseDelay*2^attempt.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.BaseDelay
+	if d <= 0 {
+		d = DefaultRetryPolicy.BaseDelay
+	}
+	for i := 0; i < attempt && (p.MaxDelay <= 0 || d < p.MaxDelay); i++ {
+		d *= 2
+	}
+	d = p.clamp(d)
+	half := d / 2
+	return half + rand.N(half+1)
+}
+
+func (p RetryPolicy) clamp(d time.Duration) time.Duration {
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		return p.MaxDelay
+	}
+	return d
+}
+
+func retryable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == http.StatusTooManyRequests || apiErr.StatusCode >= 500
+	}
+	var reqErr *RequestError
+	return errors.As(err, &reqErr) && reqErr.Op != "creating request"
+}
+
+// parseRetryAfter decodes a Retry-After header given either in seconds or
+// as an HTTP date. It returns 0 when the header is absent or invalid.
+func parseRetryAfter(v string, now time.Time) time.Duration {
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0
+		}
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := t.Sub(now); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// RateLimiter is a token bucket that spaces out requests so a process, or
+// every process sharing its state file, stays under the API quota. It is
+// safe for concurrent use.
+type RateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	burst    int
+	tokens   float64
+	last     time.Time
+
+	// path, when set, is the file the bucket is kept in, so that separate
+	// invocations running at the same time draw on the same tokens.
+	path string
+}
+
+// NewRateLimiter allows perMinute requests per minute with bursts of up to
+// burst requests. It returns nil, meaning unlimited, if perMinute is not
+// positive.
+func NewRateLimiter(perMinute, burst int) *RateLimiter {
+	if perMinute <= 0 {
+		return nil
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{
+		interval: time.Minute / time.Duration(perMinute),
+		burst:    burst,
+		tokens:   float64(burst),
+		last:     time.Now(),
+	}
+}
+
+// NewSharedRateLimiter is like NewRateLimiter, but keeps the bucket in the
+// file at path, locked while in use, so every process using the same file
+// shares the limit. When the file cannot be used the limiter falls back to
+// a bucket of its own.
+func NewSharedRateLimiter(path string, perMinute, burst int) *RateLimiter {
+	l := NewRateLimiter(perMinute, burst)
+	if l != nil {
+		l.path = path
+	}
+	return l
+}
+
+// DefaultRateLimiterPath returns the state file the CLI's shared rate
+// limiter uses, next to the response cache.
+func DefaultRateLimiterPath() (string, error) {
+	dir, err := DefaultCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(dir), "ratelimit.json"), nil
+}
+
+// Wait blocks until a request may be made or ctx is done.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	for {
+		d := l.reserve()
+		if d == 0 {
+			return nil
+		}
+		if err := sleep(ctx, d); err != nil {
+			return err
+		}
+	}
+}
+
+// reserve takes a token if one is available and otherwise returns how long
+// until the next one is.
+func (l *RateLimiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.path != "" {
+		if d, err := l.reserveShared(); err == nil {
+			return d
+		}
+	}
+	return l.take(&l.tokens, &l.last, time.Now())
+}
+
+// take refills a bucket holding tokens as of last up to now, and takes a
+// token from it or returns how long until one is available.
+func (l *RateLimiter) take(tokens *float64, last *time.Time, now time.Time) time.Duration {
+	*tokens += float64(now.Sub(*last)) / float64(l.interval)
+	if *tokens > float64(l.burst) {
+		*tokens = float64(l.burst)
+	}
+	*last = now
+
+	if *tokens >= 1 {
+		*tokens--
+		return 0
+	}
+	return max(time.Duration((1-*tokens)*float64(l.interval)), time.Millisecond)
+}
+
+// limiterState is the bucket as kept in a shared limiter's file.
+type limiterState struct {
+	Tokens float64   `json:"tokens"`
+	Last   time.Time `json:"last"`
+}
+
+// reserveShared is reserve for a bucket kept in the limiter's file. A
+// missing or unreadable file starts a full bucket.
+func (l *RateLimiter) reserveShared() (time.Duration, error) {
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o700); err != nil {
+		return 0, err
+	}
+	f, err := os.OpenFile(l.path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	if err := lockFile(f); err != nil {
+		return 0, err
+	}
+	defer unlockFile(f)
+
+	now := time.Now()
+	state := limiterState{Tokens: float64(l.burst), Last: now}
+	if data, err := io.ReadAll(f); err == nil && len(data) > 0 {
+		if err := json.Unmarshal(data, &state); err != nil || state.Last.After(now) {
+			state = limiterState{Tokens: float64(l.burst), Last: now}
+		}
+	}
+	d := l.take(&state.Tokens, &state.Last, now)
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return 0, err
+	}
+	if err := f.Truncate(0); err != nil {
+		return 0, err
+	}
+	if _, err := f.WriteAt(data, 0); err != nil {
+		return 0, err
+	}
+	return d, nil
+}
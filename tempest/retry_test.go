@@ -0,0 +1,49 @@
+package tempest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterReserve(t *testing.T) {
+	l := NewRateLimiter(60, 1)
+	if d := l.reserve(); d != 0 {
+		t.Fatalf("first reserve waits %v, want 0", d)
+	}
+	if d := l.reserve(); d < 900*time.Millisecond || d > time.Second {
+		t.Errorf("second reserve waits %v, want about 1s", d)
+	}
+	if NewRateLimiter(0, 1) != nil {
+		t.Error("NewRateLimiter(0, 1) is not nil")
+	}
+}
+
+// TestSharedRateLimiter stands in two processes with two limiters on the
+// same file: the second must wait for the token the first took.
+func TestSharedRateLimiter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "ratelimit.json")
+	first := NewSharedRateLimiter(path, 60, 1)
+	second := NewSharedRateLimiter(path, 60, 1)
+
+	if d := first.reserve(); d != 0 {
+		t.Fatalf("first reserve waits %v, want 0", d)
+	}
+	if d := second.reserve(); d < 900*time.Millisecond || d > time.Second {
+		t.Errorf("reserve by the other limiter waits %v, want about 1s", d)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("state file not written: %v", err)
+	}
+}
+
+func TestSharedRateLimiterBadState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ratelimit.json")
+	if err := os.WriteFile(path, []byte("not json"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if d := NewSharedRateLimiter(path, 60, 1).reserve(); d != 0 {
+		t.Errorf("reserve with a corrupt state file waits %v, want 0", d)
+	}
+}
@@ -0,0 +1,58 @@
+package tempest
+
+// Station is the response body of the stations endpoints. Both the listing
+// and the single station lookup return a list of stations.
+type Station struct {
+	Stations []struct {
+		Capabilities []struct {
+			Capability      string  `json:"capability"`
+			DeviceID        int     `json:"device_id"`
+			Environment     string  `json:"environment"`
+			Agl             float64 `json:"agl,omitempty"`
+			ShowPrecipFinal bool    `json:"show_precip_final,omitempty"`
+		} `json:"capabilities"`
+		CreatedEpoch int `json:"created_epoch"`
+		Devices      []struct {
+			DeviceID   int `json:"device_id"`
+			DeviceMeta struct {
+				Agl             float64 `json:"agl"`
+				Environment     string  `json:"environment"`
+				Name            string  `json:"name"`
+				WifiNetworkName string  `json:"wifi_network_name"`
+			} `json:"device_meta"`
+			DeviceType       string `json:"device_type"`
+			FirmwareRevision string `json:"firmware_revision"`
+			HardwareRevision string `json:"hardware_revision"`
+			SerialNumber     string `json:"serial_number"`
+			DeviceSettings   struct {
+				ShowPrecipFinal bool `json:"show_precip_final"`
+			} `json:"device_settings,omitempty"`
+		} `json:"devices"`
+		IsLocalMode       bool    `json:"is_local_mode"`
+		LastModifiedEpoch int     `json:"last_modified_epoch"`
+		Latitude          float64 `json:"latitude"`
+		LocationID        int     `json:"location_id"`
+		Longitude         float64 `json:"longitude"`
+		Name              string  `json:"name"`
+		PublicName        string  `json:"public_name"`
+		State             int     `json:"state"`
+		StationID         int     `json:"station_id"`
+		StationItems      []struct {
+			DeviceID       int    `json:"device_id"`
+			Item           string `json:"item"`
+			LocationID     int    `json:"location_id"`
+			LocationItemID int    `json:"location_item_id"`
+			Sort           int    `json:"sort"`
+			StationID      int    `json:"station_id"`
+			StationItemID  int    `json:"station_item_id"`
+		} `json:"station_items"`
+		StationMeta struct {
+			Elevation   float64 `json:"elevation"`
+			ShareWithWf bool    `json:"share_with_wf"`
+			ShareWithWu bool    `json:"share_with_wu"`
+		} `json:"station_meta"`
+		Timezone              string `json:"timezone"`
+		TimezoneOffsetMinutes int    `json:"timezone_offset_minutes"`
+	} `json:"stations"`
+	Status Status `json:"status"`
+}
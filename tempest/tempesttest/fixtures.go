@@ -0,0 +1,338 @@
+package tempesttest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+
+	"tempest-cli/tempest"
+)
+
+// IDs of the station and devices in the default fixtures.
+const (
+	StationID = 1000
+	HubID     = 2000
+	DeviceID  = 2001
+)
+
+// Fixtures holds the response bodies the mock serves, as raw JSON. A nil
+// field is generated from a simple model of the weather at the default
+// station.
+type Fixtures struct {
+	// Stations is the /stations response. /stations/{id} serves the entry
+	// with that station_id from it.
+	Stations []byte
+	// Observation is the /observations/station/{id} response.
+	Observation []byte
+	// Forecast is the /better_forecast response. Values are converted to
+	// the units a request asks for, from the units named in its units block.
+	Forecast []byte
+	// DeviceObservations is the /observations/device/{id} response. Only
+	// the rows within the requested time range are served.
+	DeviceObservations []byte
+}
+
+// fixtureFiles maps the file names LoadFixtures looks for to the field
+// they fill.
+var fixtureFiles = []struct {
+	name  string
+	field func(*Fixtures) *[]byte
+}{
+	{"stations.json", func(f *Fixtures) *[]byte { return &f.Stations }},
+	{"observation.json", func(f *Fixtures) *[]byte { return &f.Observation }},
+	{"forecast.json", func(f *Fixtures) *[]byte { return &f.Forecast }},
+	{"device_observations.json", func(f *Fixtures) *[]byte { return &f.DeviceObservations }},
+}
+
+// LoadFixtures reads the fixtures found in dir: stations.json,
+// observation.json, forecast.json and device_observations.json. Missing
+// files leave their field nil.
+func LoadFixtures(dir string) (Fixtures, error) {
+	var fx Fixtures
+	for _, f := range fixtureFiles {
+		data, err := os.ReadFile(filepath.Join(dir, f.name))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return Fixtures{}, err
+		}
+		if !json.Valid(data) {
+			return Fixtures{}, fmt.Errorf("fixture %s: invalid JSON", f.name)
+		}
+		*f.field(&fx) = data
+	}
+	return fx, nil
+}
+
+// Files lists the fixture files LoadFixtures reads.
+func Files() []string {
+	names := make([]string, len(fixtureFiles))
+	for i, f := range fixtureFiles {
+		names[i] = f.name
+	}
+	return names
+}
+
+// stationTimezone is the timezone of the default station.
+var stationTimezone = time.FixedZone("CST", -6*60*60)
+
+// defaultStations is the /stations response of the default fixtures.
+var defaultStations = fmt.Sprintf(`{
+  "status": {"status_code": 0, "status_message": "SUCCESS"},
+  "stations": [{
+    "station_id": %[1]d,
+    "name": "Mock Station",
+    "public_name": "Mock Station",
+    "latitude": 44.86,
+    "longitude": -93.3,
+    "timezone": "America/Chicago",
+    "timezone_offset_minutes": -360,
+    "station_meta": {"elevation": 308, "share_with_wf": true, "share_with_wu": false},
+    "devices": [
+      {"device_id": %[2]d, "serial_number": "HB-00001000", "device_type": "HB",
+       "hardware_revision": "1", "firmware_revision": "177",
+       "device_meta": {"agl": 0, "environment": "indoor", "name": "HB-00001000", "wifi_network_name": ""}},
+      {"device_id": %[3]d, "serial_number": "ST-00002001", "device_type": "ST",
+       "hardware_revision": "1", "firmware_revision": "172",
+       "device_meta": {"agl": 2, "environment": "outdoor", "name": "ST-00002001", "wifi_network_name": ""}}
+    ]
+  }]
+}`, StationID, HubID, DeviceID)
+
+// weather is the modelled state of the default station at a moment, in
+// metric units.
+type weather struct {
+	temp, humidity, pressure     float64
+	windAvg, windGust, windLull  float64
+	windDir                      int
+	lux, solar, uv               float64
+	pressureTrend                string
+	feelsLike, dewPoint, wetBulb float64
+}
+
+// weatherAt models a mild day: temperature and light follow the sun,
+// humidity the opposite, pressure drifts over three days and the wind
+// veers slowly. The same moment always gives the same weather.
+func weatherAt(t time.Time) weather {
+	local := t.In(stationTimezone)
+	hour := float64(local.Hour()) + float64(local.Minute())/60
+	day := 2 * math.Pi * (hour - 9) / 24
+	sun := math.Max(0, math.Sin(math.Pi*(hour-6)/12))
+	secs := float64(t.Unix())
+
+	w := weather{
+		temp:     15 + 7*math.Sin(day),
+		humidity: 65 - 20*math.Sin(day),
+		pressure: 1013 + 4*math.Sin(2*math.Pi*secs/(3*86400)),
+		windAvg:  3 + 1.5*math.Sin(2*math.Pi*secs/1800),
+		windDir:  int(math.Mod(200+40*math.Sin(2*math.Pi*secs/7200)+360, 360)),
+		lux:      90000 * sun,
+		solar:    750 * sun,
+		uv:       math.Round(8*sun*10) / 10,
+	}
+	w.windGust = w.windAvg * 1.6
+	w.windLull = w.windAvg * 0.4
+	w.pressureTrend = "steady"
+	switch slope := math.Cos(2 * math.Pi * secs / (3 * 86400)); {
+	case slope > 0.3:
+		w.pressureTrend = "rising"
+	case slope < -0.3:
+		w.pressureTrend = "falling"
+	}
+	w.dewPoint = dewPoint(w.temp, w.humidity)
+	w.feelsLike = w.temp
+	w.wetBulb = w.temp - (w.temp-w.dewPoint)/3
+	return w
+}
+
+// dewPoint is the Magnus approximation of the dew point.
+func dewPoint(temp, humidity float64) float64 {
+	const b, c = 17.625, 243.04
+	g := math.Log(humidity/100) + b*temp/(c+temp)
+	return c * g / (b - g)
+}
+
+func round(v float64, decimals int) float64 {
+	p := math.Pow(10, float64(decimals))
+	return math.Round(v*p) / p
+}
+
+// obsRow returns w as an obs_st array.
+func (w weather) obsRow(t time.Time) []float64 {
+	return []float64{
+		float64(t.Unix()), round(w.windLull, 2), round(w.windAvg, 2), round(w.windGust, 2), float64(w.windDir),
+		3, round(w.pressure, 1), round(w.temp, 1), round(w.humidity, 0), round(w.lux, 0),
+		w.uv, round(w.solar, 0), 0, 0,
+		0, 0, 2.6, 1,
+		0, 0, 0, 0,
+	}
+}
+
+// observation returns the /observations/station response for t.
+func observation(t time.Time) map[string]any {
+	w := weatherAt(t)
+	return map[string]any{
+		"status":       map[string]any{"status_code": 0, "status_message": "SUCCESS"},
+		"station_id":   StationID,
+		"station_name": "Mock Station",
+		"public_name":  "Mock Station",
+		"latitude":     44.86,
+		"longitude":    -93.3,
+		"elevation":    308,
+		"is_public":    true,
+		"timezone":     "America/Chicago",
+		"station_units": map[string]string{
+			"units_temp": "c", "units_wind": "mps", "units_pressure": "mb", "units_precip": "mm",
+			"units_distance": "km", "units_direction": "degrees", "units_other": "metric",
+		},
+		"outdoor_keys": []string{"timestamp", "air_temperature", "barometric_pressure", "station_pressure",
+			"relative_humidity", "wind_avg", "wind_direction", "wind_gust", "wind_lull", "uv", "solar_radiation", "brightness"},
+		"obs": []map[string]any{{
+			"timestamp":                  t.Unix(),
+			"air_temperature":            round(w.temp, 1),
+			"barometric_pressure":        round(w.pressure-36, 1),
+			"station_pressure":           round(w.pressure-36, 1),
+			"sea_level_pressure":         round(w.pressure, 1),
+			"pressure_trend":             w.pressureTrend,
+			"relative_humidity":          int(w.humidity),
+			"dew_point":                  round(w.dewPoint, 1),
+			"feels_like":                 round(w.feelsLike, 1),
+			"heat_index":                 round(w.temp, 1),
+			"wind_chill":                 round(w.temp, 1),
+			"wet_bulb_temperature":       round(w.wetBulb, 1),
+			"wet_bulb_globe_temperature": round(w.wetBulb+2, 1),
+			"delta_t":                    round(w.temp-w.wetBulb, 1),
+			"air_density":                1.2,
+			"wind_avg":                   round(w.windAvg, 1),
+			"wind_gust":                  round(w.windGust, 1),
+			"wind_lull":                  round(w.windLull, 1),
+			"wind_direction":             w.windDir,
+			"brightness":                 int(w.lux),
+			"solar_radiation":            int(w.solar),
+			"uv":                         w.uv,
+			"precip":                     0,
+			"precip_accum_last_1hr":      0,
+			"precip_accum_local_day":     0,
+			"lightning_strike_count":     0,
+		}},
+	}
+}
+
+// forecast returns the /better_forecast response for t, in metric units.
+func forecast(t time.Time) tempest.Forecast {
+	w := weatherAt(t)
+	var f tempest.Forecast
+	f.LocationName = "Mock Station"
+	f.Latitude, f.Longitude = 44.86, -93.3
+	f.Timezone = "America/Chicago"
+	f.TimezoneOffsetMinutes = -360
+	f.Station.StationID = StationID
+	f.Station.IsStationOnline = true
+	f.Station.Elevation = 308
+	f.Units = tempest.ForecastUnits{
+		UnitsAirDensity: "kg/m3", UnitsBrightness: "lux", UnitsDistance: "km", UnitsOther: "metric",
+		UnitsPrecip: "mm", UnitsPressure: "mb", UnitsSolarRadiation: "w/m2", UnitsTemp: "c", UnitsWind: "mps",
+	}
+	f.CurrentConditions = tempest.ForecastCurrentConditions{
+		AirDensity:              1.2,
+		AirTemperature:          round(w.temp, 1),
+		Brightness:              int(w.lux),
+		Conditions:              conditions(w),
+		DeltaT:                  round(w.temp-w.wetBulb, 1),
+		DewPoint:                round(w.dewPoint, 1),
+		FeelsLike:               round(w.feelsLike, 1),
+		Icon:                    icon(w, t),
+		PressureTrend:           w.pressureTrend,
+		RelativeHumidity:        int(w.humidity),
+		SeaLevelPressure:        round(w.pressure, 1),
+		SolarRadiation:          int(w.solar),
+		StationPressure:         round(w.pressure-36, 1),
+		Time:                    int(t.Unix()),
+		Uv:                      int(w.uv),
+		WetBulbGlobeTemperature: round(w.wetBulb+2, 1),
+		WetBulbTemperature:      round(w.wetBulb, 1),
+		WindAvg:                 round(w.windAvg, 1),
+		WindDirection:           w.windDir,
+		WindDirectionCardinal:   cardinal(w.windDir),
+		WindGust:                round(w.windGust, 1),
+	}
+
+	local := t.In(stationTimezone)
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, stationTimezone)
+	for d := 0; d < 10; d++ {
+		start := midnight.AddDate(0, 0, d)
+		high, low := weatherAt(start.Add(15*time.Hour)), weatherAt(start.Add(3*time.Hour))
+		f.Forecast.Daily = append(f.Forecast.Daily, tempest.ForecastDaily{
+			AirTempHigh:       round(high.temp, 0),
+			AirTempLow:        round(low.temp, 0),
+			Conditions:        conditions(high),
+			DayNum:            start.Day(),
+			DayStartLocal:     int(start.Unix()),
+			Icon:              icon(high, start.Add(12*time.Hour)),
+			MonthNum:          int(start.Month()),
+			PrecipIcon:        "chance-rain",
+			PrecipProbability: 10 * (d % 3),
+			PrecipType:        "rain",
+			Sunrise:           int(start.Add(6 * time.Hour).Unix()),
+			Sunset:            int(start.Add(18 * time.Hour).Unix()),
+		})
+	}
+	hour := local.Truncate(time.Hour).Add(time.Hour)
+	for h := 0; h < 48; h++ {
+		at := hour.Add(time.Duration(h) * time.Hour)
+		hw := weatherAt(at)
+		lt := at.In(stationTimezone)
+		f.Forecast.Hourly = append(f.Forecast.Hourly, tempest.ForecastHourly{
+			AirTemperature:        round(hw.temp, 0),
+			Conditions:            conditions(hw),
+			FeelsLike:             round(hw.feelsLike, 0),
+			Icon:                  icon(hw, at),
+			LocalDay:              lt.Day(),
+			LocalHour:             lt.Hour(),
+			PrecipIcon:            "chance-rain",
+			PrecipType:            "rain",
+			RelativeHumidity:      int(hw.humidity),
+			SeaLevelPressure:      round(hw.pressure, 1),
+			StationPressure:       round(hw.pressure-36, 1),
+			Time:                  int(at.Unix()),
+			Uv:                    hw.uv,
+			WindAvg:               round(hw.windAvg, 0),
+			WindDirection:         hw.windDir,
+			WindDirectionCardinal: cardinal(hw.windDir),
+			WindGust:              round(hw.windGust, 0),
+		})
+	}
+	return f
+}
+
+func conditions(w weather) string {
+	if w.lux > 20000 {
+		return "Clear"
+	}
+	return "Partly Cloudy"
+}
+
+func icon(w weather, t time.Time) string {
+	hour := t.In(stationTimezone).Hour()
+	night := hour < 6 || hour >= 18
+	switch {
+	case night && w.lux == 0:
+		return "clear-night"
+	case w.lux > 20000:
+		return "clear-day"
+	case night:
+		return "partly-cloudy-night"
+	}
+	return "partly-cloudy-day"
+}
+
+func cardinal(deg int) string {
+	dirs := []string{"N", "NNE", "NE", "ENE", "E", "ESE", "SE", "SSE", "S", "SSW", "SW", "WSW", "W", "WNW", "NW", "NNW"}
+	return dirs[int(math.Round(float64(deg)/22.5))%16]
+}
@@ -0,0 +1,304 @@
+// Package tempesttest runs a stand-in for the Tempest REST and WebSocket
+// APIs, for developing and testing without network access or a station.
+//
+// The REST API is served under RESTPath and the WebSocket API at WSPath.
+// Responses come from Fixtures; whatever is not given is generated from a
+// simple model of a mild day at a station with ID StationID, whose Tempest
+// has device ID DeviceID.
+package tempesttest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// RESTPath is the root of the mock REST API.
+	RESTPath = "/swd/rest"
+	// WSPath is the endpoint of the mock WebSocket API.
+	WSPath = "/swd/data"
+)
+
+// Handler serves the mock API. Create one with NewHandler.
+type Handler struct {
+	fixtures      Fixtures
+	token         string
+	obsInterval   time.Duration
+	rapidInterval time.Duration
+	logf          func(format string, args ...any)
+	now           func() time.Time
+	mux           *http.ServeMux
+}
+
+// Option configures a Handler.
+type Option func(*Handler)
+
+// WithToken makes the mock reject requests that do not carry token. By
+// default any non-empty token is accepted.
+func WithToken(token string) Option {
+	return func(h *Handler) {
+		h.token = token
+	}
+}
+
+// WithIntervals sets how often WebSocket subscribers receive obs_st and
+// rapid_wind messages. The defaults match the real API: a minute and three
+// seconds.
+func WithIntervals(obs, rapid time.Duration) Option {
+	return func(h *Handler) {
+		h.obsInterval = obs
+		h.rapidInterval = rapid
+	}
+}
+
+// WithLogger sets a function that receives a line for every request and
+// WebSocket subscription.
+func WithLogger(logf func(format string, args ...any)) Option {
+	return func(h *Handler) {
+		h.logf = logf
+	}
+}
+
+// NewHandler returns a Handler serving fx.
+func NewHandler(fx Fixtures, opts ...Option) *Handler {
+	h := &Handler{
+		fixtures:      fx,
+		obsInterval:   time.Minute,
+		rapidInterval: 3 * time.Second,
+		logf:          func(string, ...any) {},
+		now:           time.Now,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	if h.fixtures.Stations == nil {
+		h.fixtures.Stations = []byte(defaultStations)
+	}
+
+	h.mux = http.NewServeMux()
+	h.mux.HandleFunc("GET "+RESTPath+"/stations", h.serveStations)
+	h.mux.HandleFunc("GET "+RESTPath+"/stations/{id}", h.serveStation)
+	h.mux.HandleFunc("GET "+RESTPath+"/observations/station/{id}", h.serveObservation)
+	h.mux.HandleFunc("GET "+RESTPath+"/observations/device/{id}", h.serveDeviceObservations)
+	h.mux.HandleFunc("GET "+RESTPath+"/better_forecast", h.serveForecast)
+	h.mux.HandleFunc("GET "+WSPath, h.serveWS)
+	return h
+}
+
+// ServeHTTP checks the token and routes the request.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.logf("%s %s", r.Method, r.URL.Path)
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if token == "" {
+		token = r.URL.Query().Get("token")
+	}
+	if token == "" || h.token != "" && token != h.token {
+		writeStatus(w, http.StatusUnauthorized, "UNAUTHORIZED")
+		return
+	}
+	h.mux.ServeHTTP(w, r)
+}
+
+// Server is a Handler listening on a local port, like httptest.Server.
+type Server struct {
+	// URL is the REST API root, to pass to tempest.WithBaseURL.
+	URL string
+	// WSURL is the WebSocket endpoint, to pass to tempest.WithWSURL.
+	WSURL string
+
+	srv *httptest.Server
+}
+
+// NewServer starts a Server serving fx. Call Close when done.
+func NewServer(fx Fixtures, opts ...Option) *Server {
+	srv := httptest.NewServer(NewHandler(fx, opts...))
+	return &Server{
+		URL:   srv.URL + RESTPath,
+		WSURL: "ws" + strings.TrimPrefix(srv.URL, "http") + WSPath,
+		srv:   srv,
+	}
+}
+
+// Close shuts the server down.
+func (s *Server) Close() {
+	s.srv.CloseClientConnections()
+	s.srv.Close()
+}
+
+func (h *Handler) serveStations(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, h.fixtures.Stations)
+}
+
+func (h *Handler) serveStation(w http.ResponseWriter, r *http.Request) {
+	st, ok := h.station(r.PathValue("id"))
+	if !ok {
+		writeStatus(w, http.StatusNotFound, "NOT FOUND")
+		return
+	}
+	writeValue(w, map[string]any{
+		"status":   map[string]any{"status_code": 0, "status_message": "SUCCESS"},
+		"stations": []any{st},
+	})
+}
+
+func (h *Handler) serveObservation(w http.ResponseWriter, r *http.Request) {
+	if _, ok := h.station(r.PathValue("id")); !ok {
+		writeStatus(w, http.StatusNotFound, "NOT FOUND")
+		return
+	}
+	if h.fixtures.Observation != nil {
+		writeJSON(w, h.fixtures.Observation)
+		return
+	}
+	writeValue(w, observation(h.now()))
+}
+
+func (h *Handler) serveDeviceObservations(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || !h.hasDevice(id) {
+		writeStatus(w, http.StatusNotFound, "NOT FOUND")
+		return
+	}
+	q := r.URL.Query()
+	start, err1 := strconv.ParseInt(q.Get("time_start"), 10, 64)
+	end, err2 := strconv.ParseInt(q.Get("time_end"), 10, 64)
+	if err1 != nil || err2 != nil || end < start {
+		writeStatus(w, http.StatusBadRequest, "BAD REQUEST")
+		return
+	}
+
+	resp := map[string]any{
+		"status":    map[string]any{"status_code": 0, "status_message": "SUCCESS"},
+		"device_id": id,
+		"type":      "obs_st",
+		"source":    "db",
+	}
+	var rows [][]json.Number
+	if h.fixtures.DeviceObservations != nil {
+		if err := decodeJSON(h.fixtures.DeviceObservations, &resp); err != nil {
+			writeStatus(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		var fx struct {
+			Obs [][]json.Number `json:"obs"`
+		}
+		decodeJSON(h.fixtures.DeviceObservations, &fx)
+		for _, row := range fx.Obs {
+			if len(row) == 0 {
+				continue
+			}
+			if ts, err := row[0].Int64(); err == nil && ts >= start && ts <= end {
+				rows = append(rows, row)
+			}
+		}
+	} else {
+		for ts := start - start%60; ts <= end; ts += 60 {
+			if ts < start {
+				continue
+			}
+			t := time.Unix(ts, 0)
+			var row []json.Number
+			for _, v := range weatherAt(t).obsRow(t) {
+				row = append(row, json.Number(strconv.FormatFloat(v, 'f', -1, 64)))
+			}
+			rows = append(rows, row)
+		}
+	}
+	resp["obs"] = rows
+	writeValue(w, resp)
+}
+
+func (h *Handler) serveForecast(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if _, ok := h.station(q.Get("station_id")); !ok {
+		writeStatus(w, http.StatusNotFound, "NOT FOUND")
+		return
+	}
+	data := h.fixtures.Forecast
+	if data == nil {
+		var err error
+		if data, err = json.Marshal(forecast(h.now())); err != nil {
+			writeStatus(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+	converted, err := convertForecast(data, q)
+	if err != nil {
+		writeStatus(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeValue(w, converted)
+}
+
+// station returns the entry of the stations fixture with the given ID.
+func (h *Handler) station(id string) (map[string]any, bool) {
+	var fx struct {
+		Stations []map[string]any `json:"stations"`
+	}
+	if decodeJSON(h.fixtures.Stations, &fx) != nil {
+		return nil, false
+	}
+	for _, st := range fx.Stations {
+		if n, ok := st["station_id"].(json.Number); ok && n.String() == id {
+			return st, true
+		}
+	}
+	return nil, false
+}
+
+// hasDevice reports whether a station in the fixture has the device.
+func (h *Handler) hasDevice(id int) bool {
+	var fx struct {
+		Stations []struct {
+			Devices []struct {
+				DeviceID int `json:"device_id"`
+			} `json:"devices"`
+		} `json:"stations"`
+	}
+	if json.Unmarshal(h.fixtures.Stations, &fx) != nil {
+		return false
+	}
+	for _, st := range fx.Stations {
+		for _, d := range st.Devices {
+			if d.DeviceID == id {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// decodeJSON decodes data keeping numbers exact, so fixtures are served
+// back unchanged.
+func decodeJSON(data []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
+
+func writeJSON(w http.ResponseWriter, data []byte) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+func writeValue(w http.ResponseWriter, v any) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		writeStatus(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, data)
+}
+
+// writeStatus writes an error response shaped like the real API's.
+func writeStatus(w http.ResponseWriter, code int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	fmt.Fprintf(w, `{"status":{"status_code":%d,"status_message":%q}}`, code, msg)
+}
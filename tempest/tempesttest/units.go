@@ -0,0 +1,134 @@
+package tempesttest
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"tempest-cli/units"
+)
+
+// forecastUnitFields lists, per units_* query parameter, the forecast fields
+// reported in that unit.
+var forecastUnitFields = map[string][]string{
+	"units_temp": {"air_temperature", "dew_point", "feels_like", "wet_bulb_temperature",
+		"wet_bulb_globe_temperature", "air_temp_high", "air_temp_low"},
+	"units_wind":     {"wind_avg", "wind_gust"},
+	"units_pressure": {"sea_level_pressure", "station_pressure"},
+	"units_precip":   {"precip", "precip_accum_local_day", "precip_accum_local_yesterday"},
+	"units_distance": {"lightning_strike_last_distance"},
+}
+
+// unitConverters return the conversion from metric into a unit, for every
+// unit the units package converts to with a linear formula. Beaufort is
+// refused: values are left alone when it is involved.
+var unitConverters = map[string]func(unit string) (func(float64) float64, error){
+	"units_temp": func(u string) (func(float64) float64, error) {
+		tu, err := units.ParseTempUnit(u)
+		return func(v float64) float64 { return units.Temperature(v).In(tu) }, err
+	},
+	"units_wind": func(u string) (func(float64) float64, error) {
+		su, err := units.ParseSpeedUnit(u)
+		if su == units.Beaufort {
+			return nil, fmt.Errorf("beaufort is not linear")
+		}
+		return func(v float64) float64 { return units.Speed(v).In(su) }, err
+	},
+	"units_pressure": func(u string) (func(float64) float64, error) {
+		pu, err := units.ParsePressureUnit(u)
+		return func(v float64) float64 { return units.Pressure(v).In(pu) }, err
+	},
+	"units_precip": func(u string) (func(float64) float64, error) {
+		pu, err := units.ParsePrecipUnit(u)
+		return func(v float64) float64 { return units.Precip(v).In(pu) }, err
+	},
+	"units_distance": func(u string) (func(float64) float64, error) {
+		du, err := units.ParseDistanceUnit(u)
+		return func(v float64) float64 { return units.Distance(v).In(du) }, err
+	},
+}
+
+// convertForecast converts a better_forecast body from the units named in
+// its units block to those requested by the units_* parameters of q, the
+// way the real API reports values in the units asked for.
+func convertForecast(data []byte, q url.Values) (map[string]any, error) {
+	var body map[string]any
+	if err := decodeJSON(data, &body); err != nil {
+		return nil, fmt.Errorf("forecast fixture: %w", err)
+	}
+	unitsBlock, _ := body["units"].(map[string]any)
+	if unitsBlock == nil {
+		return body, nil
+	}
+
+	for param, fields := range forecastUnitFields {
+		want := q.Get(param)
+		have, _ := unitsBlock[param].(string)
+		if want == "" || have == "" || strings.EqualFold(want, have) {
+			continue
+		}
+		conv, ok := converter(param, have, want)
+		if !ok {
+			continue
+		}
+		set := map[string]bool{}
+		for _, f := range fields {
+			set[f] = true
+		}
+		convertFields(body, set, conv)
+		unitsBlock[param] = strings.ToLower(want)
+	}
+	return body, nil
+}
+
+// converter returns a function converting values in unit from to unit to.
+// Each unit's conversion from metric is linear, so inverting it needs only
+// two points.
+func converter(param, from, to string) (func(float64) float64, bool) {
+	inFrom, err := unitConverters[param](from)
+	if err != nil {
+		return nil, false
+	}
+	inTo, err := unitConverters[param](to)
+	if err != nil {
+		return nil, false
+	}
+	offset := inFrom(0)
+	scale := inFrom(1) - offset
+	return func(v float64) float64 {
+		return inTo((v - offset) / scale)
+	}, true
+}
+
+// convertFields applies conv to every field named in fields, anywhere in v.
+// Whole numbers stay whole, since some forecast fields are integers.
+func convertFields(v any, fields map[string]bool, conv func(float64) float64) {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, child := range v {
+			n, ok := child.(json.Number)
+			if !ok || !fields[k] {
+				convertFields(child, fields, conv)
+				continue
+			}
+			f, err := n.Float64()
+			if err != nil {
+				continue
+			}
+			out := conv(f)
+			if strings.ContainsAny(n.String(), ".eE") {
+				out = math.Round(out*100) / 100
+			} else {
+				out = math.Round(out)
+			}
+			v[k] = json.Number(strconv.FormatFloat(out, 'f', -1, 64))
+		}
+	case []any:
+		for _, child := range v {
+			convertFields(child, fields, conv)
+		}
+	}
+}
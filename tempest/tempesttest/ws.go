@@ -0,0 +1,155 @@
+package tempesttest
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+var upgrader = websocket.Upgrader{
+	CheckOrigin: func(*http.Request) bool { return true },
+}
+
+// wsRequest is a listen_start, listen_stop, listen_rapid_start or
+// listen_rapid_stop request.
+type wsRequest struct {
+	Type     string `json:"type"`
+	DeviceID int    `json:"device_id"`
+	ID       string `json:"id"`
+}
+
+// wsSession is one WebSocket connection and its subscriptions.
+type wsSession struct {
+	h    *Handler
+	conn *websocket.Conn
+
+	// mu serializes writes and guards subs.
+	mu   sync.Mutex
+	subs map[string]chan struct{}
+}
+
+// serveWS answers subscription requests with an ack and streams modelled
+// obs_st and rapid_wind messages to the devices subscribed to.
+func (h *Handler) serveWS(w http.ResponseWriter, r *http.Request) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	s := &wsSession{h: h, conn: conn, subs: map[string]chan struct{}{}}
+	defer s.close()
+
+	s.send(map[string]any{"type": "connection_opened"})
+	for {
+		var req wsRequest
+		if err := conn.ReadJSON(&req); err != nil {
+			return
+		}
+		h.logf("ws %s device %d", req.Type, req.DeviceID)
+		s.send(map[string]any{"type": "ack", "id": req.ID})
+		switch req.Type {
+		case "listen_start":
+			s.start("obs", req.DeviceID, h.obsInterval, s.obs)
+		case "listen_rapid_start":
+			s.start("rapid", req.DeviceID, h.rapidInterval, s.rapid)
+		case "listen_stop":
+			s.stop("obs", req.DeviceID)
+		case "listen_rapid_stop":
+			s.stop("rapid", req.DeviceID)
+		}
+	}
+}
+
+// start sends msg for deviceID now and then every interval until the
+// subscription is stopped.
+func (s *wsSession) start(kind string, deviceID int, interval time.Duration, msg func(int, time.Time) any) {
+	key := subKey(kind, deviceID)
+	s.mu.Lock()
+	if _, ok := s.subs[key]; ok {
+		s.mu.Unlock()
+		return
+	}
+	stop := make(chan struct{})
+	s.subs[key] = stop
+	s.mu.Unlock()
+
+	go func() {
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			if !s.send(msg(deviceID, s.h.now())) {
+				return
+			}
+			select {
+			case <-stop:
+				return
+			case <-t.C:
+			}
+		}
+	}()
+}
+
+func (s *wsSession) stop(kind string, deviceID int) {
+	key := subKey(kind, deviceID)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if stop, ok := s.subs[key]; ok {
+		close(stop)
+		delete(s.subs, key)
+	}
+}
+
+func (s *wsSession) close() {
+	s.mu.Lock()
+	for key, stop := range s.subs {
+		close(stop)
+		delete(s.subs, key)
+	}
+	s.mu.Unlock()
+	s.conn.Close()
+}
+
+// send writes v, reporting false once the connection has failed.
+func (s *wsSession) send(v any) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+	return s.conn.WriteJSON(v) == nil
+}
+
+func (s *wsSession) obs(deviceID int, t time.Time) any {
+	w := weatherAt(t)
+	return map[string]any{
+		"type":      "obs_st",
+		"device_id": deviceID,
+		"source":    "mock",
+		"obs":       [][]float64{w.obsRow(t)},
+		"summary": map[string]any{
+			"pressure_trend":       w.pressureTrend,
+			"strike_count_1h":      0,
+			"strike_count_3h":      0,
+			"precip_total_1h":      0.0,
+			"feels_like":           round(w.feelsLike, 1),
+			"heat_index":           round(w.temp, 1),
+			"wind_chill":           round(w.temp, 1),
+			"dew_point":            round(w.dewPoint, 1),
+			"wet_bulb_temperature": round(w.wetBulb, 1),
+			"air_density":          1.2,
+		},
+	}
+}
+
+func (s *wsSession) rapid(deviceID int, t time.Time) any {
+	w := weatherAt(t)
+	return map[string]any{
+		"type":      "rapid_wind",
+		"device_id": deviceID,
+		"ob":        []float64{float64(t.Unix()), round(w.windAvg, 2), float64(w.windDir)},
+	}
+}
+
+func subKey(kind string, deviceID int) string {
+	return kind + "/" + strconv.Itoa(deviceID)
+}
@@ -0,0 +1,378 @@
+package tempest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// DefaultWSURL is the endpoint of the Tempest WebSocket API.
+	DefaultWSURL = "wss://ws.weatherflow.com/swd/data"
+
+	// DefaultPingInterval is how often a connected WSClient pings the server.
+	DefaultPingInterval = 30 * time.Second
+
+	// DefaultPongWait is how long a WSClient waits past a ping interval for
+	// any message or pong before it treats the connection as dead.
+	DefaultPongWait = 15 * time.Second
+)
+
+// DefaultReconnectPolicy is the backoff a WSClient created without
+// WithReconnectBackoff waits between connection attempts. Its MaxRetries is
+// ignored; a WSClient keeps trying until its context is cancelled.
+var DefaultReconnectPolicy = RetryPolicy{
+	BaseDelay: time.Second,
+	MaxDelay:  2 * time.Minute,
+}
+
+// WSState is the connection state of a WSClient.
+type WSState int
+
+const (
+	// WSConnecting means a connection attempt is in progress.
+	WSConnecting WSState = iota
+	// WSConnected means the client is connected and subscribed.
+	WSConnected
+	// WSReconnecting means the connection was lost or could not be made and
+	// the client is waiting before the next attempt.
+	WSReconnecting
+	// WSClosed means Run has returned.
+	WSClosed
+)
+
+func (s WSState) String() string {
+	switch s {
+	case WSConnecting:
+		return "connecting"
+	case WSConnected:
+		return "connected"
+	case WSReconnecting:
+		return "reconnecting"
+	case WSClosed:
+		return "closed"
+	}
+	return fmt.Sprintf("WSState(%d)", int(s))
+}
+
+// WSStateChange describes a change of connection state.
+type WSStateChange struct {
+	State WSState
+	// Attempt counts the connection attempts made since the client was last
+	// connected, starting at 1.
+	Attempt int
+	// Delay is the wait before the next attempt, when reconnecting.
+	Delay time.Duration
+	// Err is why the connection was lost or could not be made, if it was.
+	Err error
+}
+
+// WSClient streams messages from the Tempest WebSocket API, reconnecting
+// with jittered exponential backoff whenever the connection drops and
+// subscribing again to every device it was listening to. The zero value is
+// not usable; create one with NewWSClient.
+type WSClient struct {
+	url          string
+	token        string
+	dialer       *websocket.Dialer
+	pingInterval time.Duration
+	pongWait     time.Duration
+	backoff      RetryPolicy
+	onState      func(WSStateChange)
+
+	// wake cuts short the wait before a reconnect; see Reconnect.
+	wake chan struct{}
+
+	// mu guards the fields below and serializes writes to conn.
+	mu      sync.Mutex
+	devices []int
+	conn    *websocket.Conn
+	running bool
+}
+
+// WSOption configures a WSClient.
+type WSOption func(*WSClient)
+
+// WithWSURL points the client at a different WebSocket endpoint, such as a
+// local stand-in server.
+func WithWSURL(u string) WSOption {
+	return func(c *WSClient) {
+		c.url = u
+	}
+}
+
+// WithDialer replaces the dialer used to connect.
+func WithDialer(d *websocket.Dialer) WSOption {
+	return func(c *WSClient) {
+		c.dialer = d
+	}
+}
+
+// WithPingInterval sets how often the client pings the server and how long
+// past that it waits for a reply before reconnecting.
+func WithPingInterval(interval, pongWait time.Duration) WSOption {
+	return func(c *WSClient) {
+		c.pingInterval = interval
+		c.pongWait = pongWait
+	}
+}
+
+// WithReconnectBackoff sets the backoff between connection attempts. Only
+// BaseDelay and MaxDelay are used.
+func WithReconnectBackoff(p RetryPolicy) WSOption {
+	return func(c *WSClient) {
+		c.backoff = p
+	}
+}
+
+// WithStateHandler sets a function called on every change of connection
+// state. It runs on the goroutine calling Run and should return quickly.
+func WithStateHandler(fn func(WSStateChange)) WSOption {
+	return func(c *WSClient) {
+		c.onState = fn
+	}
+}
+
+// NewWSClient returns a WSClient authenticating with the given personal
+// access token.
+func NewWSClient(token string, opts ...WSOption) *WSClient {
+	c := &WSClient{
+		url:          DefaultWSURL,
+		token:        token,
+		dialer:       websocket.DefaultDialer,
+		pingInterval: DefaultPingInterval,
+		pongWait:     DefaultPongWait,
+		backoff:      DefaultReconnectPolicy,
+		wake:         make(chan struct{}, 1),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Subscribe starts listening to the observations and rapid wind of a
+// device. It may be called before or during Run; the subscription is sent
+// again after every reconnect.
+func (c *WSClient) Subscribe(deviceID int) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, id := range c.devices {
+		if id == deviceID {
+			return nil
+		}
+	}
+	c.devices = append(c.devices, deviceID)
+	if c.conn == nil {
+		return nil
+	}
+	return subscribe(c.conn, deviceID)
+}
+
+// ErrWSRunning is returned by Run when the client is already running.
+var ErrWSRunning = errors.New("websocket client already running")
+
+// Run connects and calls handle with every message received until ctx is
+// cancelled, reconnecting as often as needed. Messages are read and handled
+// on the calling goroutine, one at a time, so there is never more than one
+// reader; handle should give up when ctx is done rather than block forever.
+// Run returns ctx.Err() once cancelled, or an error when the server rejects
+// the token or endpoint, which no reconnect can fix.
+func (c *WSClient) Run(ctx context.Context, handle func(raw []byte)) error {
+	c.mu.Lock()
+	if c.running {
+		c.mu.Unlock()
+		return ErrWSRunning
+	}
+	c.running = true
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		c.running = false
+		c.mu.Unlock()
+		c.setState(WSStateChange{State: WSClosed})
+	}()
+
+	attempt := 0
+	for {
+		attempt++
+		c.setState(WSStateChange{State: WSConnecting, Attempt: attempt})
+		conn, err := c.connect(ctx)
+		if err == nil {
+			// A Reconnect asked for while connecting is satisfied.
+			select {
+			case <-c.wake:
+			default:
+			}
+			c.setState(WSStateChange{State: WSConnected, Attempt: attempt})
+			var received bool
+			received, err = c.serve(ctx, conn, handle)
+			if received {
+				attempt = 1
+			}
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if permanent(err) {
+			return err
+		}
+
+		delay := c.backoff.backoff(attempt - 1)
+		c.setState(WSStateChange{State: WSReconnecting, Attempt: attempt, Delay: delay, Err: err})
+		t := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		case <-c.wake:
+			t.Stop()
+		case <-t.C:
+		}
+	}
+}
+
+// Reconnect drops the current connection, or cuts short the wait for the
+// next attempt, so that a running client connects again straight away.
+func (c *WSClient) Reconnect() {
+	select {
+	case c.wake <- struct{}{}:
+	default:
+	}
+	c.mu.Lock()
+	conn := c.conn
+	c.mu.Unlock()
+	if conn != nil {
+		conn.Close()
+	}
+}
+
+// connect dials the endpoint and subscribes to every device.
+func (c *WSClient) connect(ctx context.Context) (*websocket.Conn, error) {
+	u, err := url.Parse(c.url)
+	if err != nil {
+		return nil, fmt.Errorf("ws dial: %w", err)
+	}
+	q := u.Query()
+	q.Set("token", c.token)
+	u.RawQuery = q.Encode()
+
+	conn, resp, err := c.dialer.DialContext(ctx, u.String(), nil)
+	if err != nil {
+		if resp != nil && resp.StatusCode != http.StatusSwitchingProtocols {
+			return nil, &APIError{StatusCode: resp.StatusCode, Path: u.Host + u.Path}
+		}
+		return nil, fmt.Errorf("ws dial: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, id := range c.devices {
+		if err := subscribe(conn, id); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	c.conn = conn
+	return conn, nil
+}
+
+// serve reads from conn until it fails or ctx is cancelled, pinging the
+// server meanwhile. It reports whether any message arrived.
+func (c *WSClient) serve(ctx context.Context, conn *websocket.Conn, handle func([]byte)) (bool, error) {
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		c.keepalive(ctx, conn, done)
+	}()
+	defer func() {
+		c.mu.Lock()
+		c.conn = nil
+		c.mu.Unlock()
+		close(done)
+		conn.Close()
+		wg.Wait()
+	}()
+
+	deadline := func() {
+		conn.SetReadDeadline(time.Now().Add(c.pingInterval + c.pongWait))
+	}
+	deadline()
+	conn.SetPongHandler(func(string) error {
+		deadline()
+		return nil
+	})
+
+	received := false
+	for {
+		_, raw, err := conn.ReadMessage()
+		if err != nil {
+			return received, fmt.Errorf("ws read: %w", err)
+		}
+		received = true
+		deadline()
+		handle(raw)
+	}
+}
+
+// keepalive pings conn every ping interval until done is closed, and closes
+// conn when ctx is cancelled so that a blocked read returns.
+func (c *WSClient) keepalive(ctx context.Context, conn *websocket.Conn, done <-chan struct{}) {
+	t := time.NewTicker(c.pingInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ctx.Done():
+			conn.Close()
+			return
+		case <-t.C:
+			// WriteControl may be called concurrently with other writes.
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(c.pongWait)); err != nil {
+				conn.Close()
+				return
+			}
+		}
+	}
+}
+
+func (c *WSClient) setState(s WSStateChange) {
+	if c.onState != nil {
+		c.onState(s)
+	}
+}
+
+// wsListen is the request starting a listen_start or listen_rapid_start
+// subscription.
+type wsListen struct {
+	Type     string `json:"type"`
+	DeviceID int    `json:"device_id"`
+	ID       string `json:"id"`
+}
+
+func subscribe(conn *websocket.Conn, deviceID int) error {
+	reqs := []wsListen{
+		{Type: "listen_start", DeviceID: deviceID, ID: fmt.Sprintf("tempest-cli-obs-%d", deviceID)},
+		{Type: "listen_rapid_start", DeviceID: deviceID, ID: fmt.Sprintf("tempest-cli-rapid-%d", deviceID)},
+	}
+	for _, req := range reqs {
+		if err := conn.WriteJSON(req); err != nil {
+			return fmt.Errorf("ws %s: %w", req.Type, err)
+		}
+	}
+	return nil
+}
+
+// permanent reports whether err means reconnecting is pointless.
+func permanent(err error) bool {
+	return errors.Is(err, ErrUnauthorized) || errors.Is(err, ErrNotFound)
+}
@@ -0,0 +1,355 @@
+// Package units converts Tempest measurements between units of measure.
+//
+// Quantities are stored in the metric units the Tempest API reports raw data
+// in (°C, m/s, mb, mm, km) and converted for display with In.
+package units
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Temperature is a temperature in degrees Celsius.
+type Temperature float64
+
+// Speed is a speed in meters per second.
+type Speed float64
+
+// Pressure is a pressure in millibars (hectopascals).
+type Pressure float64
+
+// Precip is a precipitation amount in millimeters.
+type Precip float64
+
+// Distance is a distance in kilometers.
+type Distance float64
+
+// TempUnit is a unit of temperature.
+type TempUnit string
+
+const (
+	Celsius    TempUnit = "c"
+	Fahrenheit TempUnit = "f"
+	Kelvin     TempUnit = "k"
+)
+
+// SpeedUnit is a unit of wind speed.
+type SpeedUnit string
+
+const (
+	MetersPerSecond   SpeedUnit = "mps"
+	KilometersPerHour SpeedUnit = "kph"
+	MilesPerHour      SpeedUnit = "mph"
+	Knots             SpeedUnit = "kts"
+	Beaufort          SpeedUnit = "bft"
+)
+
+// PressureUnit is a unit of air pressure.
+type PressureUnit string
+
+const (
+	Millibar             PressureUnit = "mb"
+	Hectopascal          PressureUnit = "hpa"
+	InchesOfMercury      PressureUnit = "inhg"
+	MillimetersOfMercury PressureUnit = "mmhg"
+)
+
+// PrecipUnit is a unit of precipitation depth.
+type PrecipUnit string
+
+const (
+	Millimeters PrecipUnit = "mm"
+	Centimeters PrecipUnit = "cm"
+	Inches      PrecipUnit = "in"
+)
+
+// DistanceUnit is a unit of distance.
+type DistanceUnit string
+
+const (
+	Kilometers DistanceUnit = "km"
+	Miles      DistanceUnit = "mi"
+)
+
+// In returns t expressed in u.
+func (t Temperature) In(u TempUnit) float64 {
+	c := float64(t)
+	switch u {
+	case Fahrenheit:
+		return c*9/5 + 32
+	case Kelvin:
+		return c + 273.15
+	}
+	return c
+}
+
+// In returns s expressed in u. For Beaufort the result is the force number.
+func (s Speed) In(u SpeedUnit) float64 {
+	ms := float64(s)
+	switch u {
+	case KilometersPerHour:
+		return ms * 3.6
+	case MilesPerHour:
+		return ms * 2.2369363
+	case Knots:
+		return ms * 1.9438445
+	case Beaufort:
+		return float64(beaufortForce(ms))
+	}
+	return ms
+}
+
+// beaufortLimits are the upper bounds in m/s of forces 0 to 11.
+var beaufortLimits = []float64{0.5, 1.5, 3.3, 5.5, 7.9, 10.7, 13.8, 17.1, 20.7, 24.4, 28.4, 32.6}
+
+func beaufortForce(ms float64) int {
+	for force, limit := range beaufortLimits {
+		if ms < limit {
+			return force
+		}
+	}
+	return 12
+}
+
+// In returns p expressed in u.
+func (p Pressure) In(u PressureUnit) float64 {
+	mb := float64(p)
+	switch u {
+	case InchesOfMercury:
+		return mb * 0.0295299830714
+	case MillimetersOfMercury:
+		return mb * 0.750061683
+	}
+	return mb
+}
+
+// In returns p expressed in u.
+func (p Precip) In(u PrecipUnit) float64 {
+	mm := float64(p)
+	switch u {
+	case Centimeters:
+		return mm / 10
+	case Inches:
+		return mm / 25.4
+	}
+	return mm
+}
+
+// In returns d expressed in u.
+func (d Distance) In(u DistanceUnit) float64 {
+	km := float64(d)
+	if u == Miles {
+		return km * 0.621371192
+	}
+	return km
+}
+
+// Symbol returns the display symbol of u.
+func (u TempUnit) Symbol() string {
+	switch u {
+	case Fahrenheit:
+		return "°F"
+	case Kelvin:
+		return "K"
+	}
+	return "°C"
+}
+
+// Symbol returns the display symbol of u.
+func (u SpeedUnit) Symbol() string {
+	switch u {
+	case KilometersPerHour:
+		return "km/h"
+	case MilesPerHour:
+		return "mph"
+	case Knots:
+		return "kn"
+	case Beaufort:
+		return "Bft"
+	}
+	return "m/s"
+}
+
+// Symbol returns the display symbol of u.
+func (u PressureUnit) Symbol() string {
+	switch u {
+	case Hectopascal:
+		return "hPa"
+	case InchesOfMercury:
+		return "inHg"
+	case MillimetersOfMercury:
+		return "mmHg"
+	}
+	return "mb"
+}
+
+// Symbol returns the display symbol of u.
+func (u PrecipUnit) Symbol() string {
+	return string(u)
+}
+
+// Symbol returns the display symbol of u.
+func (u DistanceUnit) Symbol() string {
+	return string(u)
+}
+
+// Decimals returns the number of decimals values in u are shown with.
+func (u PressureUnit) Decimals() int {
+	if u == InchesOfMercury {
+		return 2
+	}
+	return 1
+}
+
+// Decimals returns the number of decimals values in u are shown with.
+func (u PrecipUnit) Decimals() int {
+	if u == Inches {
+		return 2
+	}
+	return 1
+}
+
+// Decimals returns the number of decimals values in u are shown with.
+func (u SpeedUnit) Decimals() int {
+	if u == Beaufort {
+		return 0
+	}
+	return 1
+}
+
+// ParseTempUnit accepts a unit code or name such as "f" or "fahrenheit".
+func ParseTempUnit(s string) (TempUnit, error) {
+	switch normalize(s) {
+	case "c", "celsius", "°c":
+		return Celsius, nil
+	case "f", "fahrenheit", "°f":
+		return Fahrenheit, nil
+	case "k", "kelvin":
+		return Kelvin, nil
+	}
+	return "", invalid("temperature", s, "c, f, k")
+}
+
+// ParseSpeedUnit accepts a unit code or name such as "kts" or "knots".
+func ParseSpeedUnit(s string) (SpeedUnit, error) {
+	switch normalize(s) {
+	case "mps", "m/s", "ms":
+		return MetersPerSecond, nil
+	case "kph", "km/h", "kmh", "kmph":
+		return KilometersPerHour, nil
+	case "mph":
+		return MilesPerHour, nil
+	case "kts", "kt", "kn", "knots":
+		return Knots, nil
+	case "bft", "beaufort":
+		return Beaufort, nil
+	}
+	return "", invalid("wind", s, "mps, kph, mph, kts, bft")
+}
+
+// ParsePressureUnit accepts a unit code such as "inhg".
+func ParsePressureUnit(s string) (PressureUnit, error) {
+	switch normalize(s) {
+	case "mb", "mbar", "millibar":
+		return Millibar, nil
+	case "hpa":
+		return Hectopascal, nil
+	case "inhg", "in":
+		return InchesOfMercury, nil
+	case "mmhg":
+		return MillimetersOfMercury, nil
+	}
+	return "", invalid("pressure", s, "mb, hpa, inhg, mmhg")
+}
+
+// ParsePrecipUnit accepts a unit code such as "in".
+func ParsePrecipUnit(s string) (PrecipUnit, error) {
+	switch normalize(s) {
+	case "mm":
+		return Millimeters, nil
+	case "cm":
+		return Centimeters, nil
+	case "in", "inch", "inches":
+		return Inches, nil
+	}
+	return "", invalid("precipitation", s, "mm, cm, in")
+}
+
+// ParseDistanceUnit accepts a unit code such as "mi".
+func ParseDistanceUnit(s string) (DistanceUnit, error) {
+	switch normalize(s) {
+	case "km", "kilometers":
+		return Kilometers, nil
+	case "mi", "miles":
+		return Miles, nil
+	}
+	return "", invalid("distance", s, "km, mi")
+}
+
+func normalize(s string) string {
+	return strings.ToLower(strings.TrimSpace(s))
+}
+
+func invalid(kind, s, valid string) error {
+	return fmt.Errorf("invalid %s unit %q, valid units are: %s", kind, s, valid)
+}
+
+// Prefs is the set of display units chosen by the user.
+type Prefs struct {
+	Temp     TempUnit     `json:"temp"`
+	Wind     SpeedUnit    `json:"wind"`
+	Pressure PressureUnit `json:"pressure"`
+	Precip   PrecipUnit   `json:"precip"`
+	Distance DistanceUnit `json:"distance"`
+}
+
+// Metric returns the default preferences, matching the API's raw units.
+func Metric() Prefs {
+	return Prefs{
+		Temp:     Celsius,
+		Wind:     MetersPerSecond,
+		Pressure: Millibar,
+		Precip:   Millimeters,
+		Distance: Kilometers,
+	}
+}
+
+// FormatTemp formats t with one decimal and its unit symbol, e.g. "21.4°C".
+func (p Prefs) FormatTemp(t Temperature) string {
+	return formatFloat(t.In(p.Temp), 1) + p.Temp.Symbol()
+}
+
+// FormatTempRound formats t rounded to a whole number, e.g. "21°C".
+func (p Prefs) FormatTempRound(t Temperature) string {
+	return formatFloat(math.Round(t.In(p.Temp)), 0) + p.Temp.Symbol()
+}
+
+// FormatSpeed formats s with its unit symbol, e.g. "3.2 m/s".
+func (p Prefs) FormatSpeed(s Speed) string {
+	return formatFloat(s.In(p.Wind), p.Wind.Decimals()) + " " + p.Wind.Symbol()
+}
+
+// FormatPressure formats pr with its unit symbol, e.g. "1013.2 mb".
+func (p Prefs) FormatPressure(pr Pressure) string {
+	return formatFloat(pr.In(p.Pressure), p.Pressure.Decimals()) + " " + p.Pressure.Symbol()
+}
+
+// FormatPrecip formats pr with its unit symbol, e.g. "2.5 mm".
+func (p Prefs) FormatPrecip(pr Precip) string {
+	return formatFloat(pr.In(p.Precip), p.Precip.Decimals()) + " " + p.Precip.Symbol()
+}
+
+// FormatDistance formats d rounded to a whole number, e.g. "12 km".
+func (p Prefs) FormatDistance(d Distance) string {
+	return formatFloat(d.In(p.Distance), 0) + " " + p.Distance.Symbol()
+}
+
+func formatFloat(v float64, decimals int) string {
+	s := strconv.FormatFloat(v, 'f', decimals, 64)
+	if s == "-0" || strings.HasPrefix(s, "-0.") && strings.Trim(s[3:], "0") == "" {
+		s = s[1:]
+	}
+	return s
+}
@@ -0,0 +1,173 @@
+package units
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestIn(t *testing.T) {
+	tests := []struct {
+		name string
+		got  float64
+		want float64
+	}{
+		{"0°C in °C", Temperature(0).In(Celsius), 0},
+		{"0°C in °F", Temperature(0).In(Fahrenheit), 32},
+		{"100°C in °F", Temperature(100).In(Fahrenheit), 212},
+		{"-40°C in °F", Temperature(-40).In(Fahrenheit), -40},
+		{"0°C in K", Temperature(0).In(Kelvin), 273.15},
+		{"-273.15°C in K", Temperature(-273.15).In(Kelvin), 0},
+
+		{"10 m/s in m/s", Speed(10).In(MetersPerSecond), 10},
+		{"10 m/s in km/h", Speed(10).In(KilometersPerHour), 36},
+		{"10 m/s in mph", Speed(10).In(MilesPerHour), 22.369363},
+		{"10 m/s in kn", Speed(10).In(Knots), 19.438445},
+		{"10 m/s in Bft", Speed(10).In(Beaufort), 5},
+
+		{"1013.25 mb in mb", Pressure(1013.25).In(Millibar), 1013.25},
+		{"1013.25 mb in hPa", Pressure(1013.25).In(Hectopascal), 1013.25},
+		{"1013.25 mb in inHg", Pressure(1013.25).In(InchesOfMercury), 29.9212524},
+		{"1013.25 mb in mmHg", Pressure(1013.25).In(MillimetersOfMercury), 760.0000},
+
+		{"25.4 mm in mm", Precip(25.4).In(Millimeters), 25.4},
+		{"25.4 mm in cm", Precip(25.4).In(Centimeters), 2.54},
+		{"25.4 mm in in", Precip(25.4).In(Inches), 1},
+
+		{"10 km in km", Distance(10).In(Kilometers), 10},
+		{"10 km in mi", Distance(10).In(Miles), 6.21371192},
+		{"1.609344 km in mi", Distance(1.609344).In(Miles), 1},
+	}
+	for _, tt := range tests {
+		// mmHg and inHg are defined to a few significant figures.
+		if math.Abs(tt.got-tt.want) > 1e-4*math.Max(1, math.Abs(tt.want)) {
+			t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestBeaufort(t *testing.T) {
+	// Each limit is the least speed of the next force.
+	tests := []struct {
+		ms   float64
+		want float64
+	}{
+		{0, 0}, {0.49, 0}, {0.5, 1},
+		{1.49, 1}, {1.5, 2},
+		{3.29, 2}, {3.3, 3},
+		{5.49, 3}, {5.5, 4},
+		{7.89, 4}, {7.9, 5},
+		{10.69, 5}, {10.7, 6},
+		{13.79, 6}, {13.8, 7},
+		{17.09, 7}, {17.1, 8},
+		{20.69, 8}, {20.7, 9},
+		{24.39, 9}, {24.4, 10},
+		{28.39, 10}, {28.4, 11},
+		{32.59, 11}, {32.6, 12},
+		{60, 12},
+	}
+	for _, tt := range tests {
+		if got := Speed(tt.ms).In(Beaufort); got != tt.want {
+			t.Errorf("%v m/s = force %v, want %v", tt.ms, got, tt.want)
+		}
+	}
+}
+
+func TestDecimals(t *testing.T) {
+	speeds := map[SpeedUnit]int{MetersPerSecond: 1, KilometersPerHour: 1, MilesPerHour: 1, Knots: 1, Beaufort: 0}
+	for u, want := range speeds {
+		if got := u.Decimals(); got != want {
+			t.Errorf("%s: %d decimals, want %d", u, got, want)
+		}
+	}
+	pressures := map[PressureUnit]int{Millibar: 1, Hectopascal: 1, InchesOfMercury: 2, MillimetersOfMercury: 1}
+	for u, want := range pressures {
+		if got := u.Decimals(); got != want {
+			t.Errorf("%s: %d decimals, want %d", u, got, want)
+		}
+	}
+	precips := map[PrecipUnit]int{Millimeters: 1, Centimeters: 1, Inches: 2}
+	for u, want := range precips {
+		if got := u.Decimals(); got != want {
+			t.Errorf("%s: %d decimals, want %d", u, got, want)
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	imperial := Prefs{Temp: Fahrenheit, Wind: MilesPerHour, Pressure: InchesOfMercury, Precip: Inches, Distance: Miles}
+	bft := Prefs{Wind: Beaufort}
+	tests := []struct {
+		got, want string
+	}{
+		{Metric().FormatTemp(21.44), "21.4°C"},
+		{imperial.FormatTemp(21.44), "70.6°F"},
+		{Prefs{Temp: Kelvin}.FormatTemp(20), "293.1K"},
+		{imperial.FormatTempRound(21.44), "71°F"},
+		{Metric().FormatTemp(-0.04), "0.0°C"},
+		{Metric().FormatTempRound(-0.4), "0°C"},
+		{Metric().FormatSpeed(3.21), "3.2 m/s"},
+		{imperial.FormatSpeed(10), "22.4 mph"},
+		{bft.FormatSpeed(10), "5 Bft"},
+		{Metric().FormatPressure(1013.25), "1013.2 mb"},
+		{imperial.FormatPressure(1013.25), "29.92 inHg"},
+		{Prefs{Pressure: MillimetersOfMercury}.FormatPressure(1013.25), "760.0 mmHg"},
+		{Metric().FormatPrecip(2.54), "2.5 mm"},
+		{imperial.FormatPrecip(2.54), "0.10 in"},
+		{Prefs{Precip: Centimeters}.FormatPrecip(2.54), "0.3 cm"},
+		{Metric().FormatDistance(12.4), "12 km"},
+		{imperial.FormatDistance(16), "10 mi"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("got %q, want %q", tt.got, tt.want)
+		}
+	}
+}
+
+func TestParseUnits(t *testing.T) {
+	parse := map[string]func(string) (string, error){
+		"temperature": func(s string) (string, error) { u, err := ParseTempUnit(s); return string(u), err },
+		"wind":        func(s string) (string, error) { u, err := ParseSpeedUnit(s); return string(u), err },
+		"pressure":    func(s string) (string, error) { u, err := ParsePressureUnit(s); return string(u), err },
+		"precip":      func(s string) (string, error) { u, err := ParsePrecipUnit(s); return string(u), err },
+		"distance":    func(s string) (string, error) { u, err := ParseDistanceUnit(s); return string(u), err },
+	}
+	tests := []struct {
+		kind, in, want string
+		wantErr        string
+	}{
+		{kind: "temperature", in: "F", want: "f"},
+		{kind: "temperature", in: " Celsius ", want: "c"},
+		{kind: "temperature", in: "°f", want: "f"},
+		{kind: "temperature", in: "kelvin", want: "k"},
+		{kind: "temperature", in: "rankine", wantErr: `invalid temperature unit "rankine", valid units are: c, f, k`},
+		{kind: "wind", in: "km/h", want: "kph"},
+		{kind: "wind", in: "knots", want: "kts"},
+		{kind: "wind", in: "Beaufort", want: "bft"},
+		{kind: "wind", in: "m/s", want: "mps"},
+		{kind: "wind", in: "fps", wantErr: `invalid wind unit "fps"`},
+		{kind: "pressure", in: "hPa", want: "hpa"},
+		{kind: "pressure", in: "inHg", want: "inhg"},
+		{kind: "pressure", in: "mmHg", want: "mmhg"},
+		{kind: "pressure", in: "atm", wantErr: `invalid pressure unit "atm"`},
+		{kind: "precip", in: "inches", want: "in"},
+		{kind: "precip", in: "cm", want: "cm"},
+		{kind: "precip", in: "ft", wantErr: `invalid precipitation unit "ft"`},
+		{kind: "distance", in: "Miles", want: "mi"},
+		{kind: "distance", in: "nm", wantErr: `invalid distance unit "nm"`},
+		{kind: "distance", in: "", wantErr: `invalid distance unit ""`},
+	}
+	for _, tt := range tests {
+		got, err := parse[tt.kind](tt.in)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%s %q: got error %v, want one containing %q", tt.kind, tt.in, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("%s %q = %q, %v; want %q", tt.kind, tt.in, got, err, tt.want)
+		}
+	}
+}